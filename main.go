@@ -8,6 +8,7 @@ import (
 	endpoints "micro-fiber-test/pkg/handlers"
 	"micro-fiber-test/pkg/logging"
 	"micro-fiber-test/pkg/middlewares"
+	"micro-fiber-test/pkg/model"
 	redisConfig "micro-fiber-test/pkg/redis"
 	"micro-fiber-test/pkg/repository/impl"
 	svcImpl "micro-fiber-test/pkg/service/impl"
@@ -26,10 +27,15 @@ import (
 )
 
 const V1Root = "/api/v1"
+const TenantsV1Root = V1Root + "/tenants"
+const TenantsV1TenantCode = TenantsV1Root + "/:tenantCode"
+const AdminV1Root = V1Root + "/admin"
 const OrgV1Root = V1Root + "/organizations"
 const OrgV1OrgCode = OrgV1Root + "/:orgCode"
 const SectorsV1Root = OrgV1OrgCode + "/sectors"
 const SectorsV1SectorCode = SectorsV1Root + "/:sectorCode"
+const TemplatesV1Root = V1Root + "/sector-templates"
+const TemplatesV1TemplateCode = TemplatesV1Root + "/:templateCode"
 const UsersV1Root = OrgV1OrgCode + "/users"
 const UsersV1UserId = UsersV1Root + "/:userId"
 
@@ -61,9 +67,15 @@ func main() {
 	orgDao := impl.NewOrgDao(dbPool, kSql)
 	sectorDao := impl.NewSectorDao(dbPool, kSql)
 	userDao := impl.NewUserDao(dbPool, kSql)
-	orgSvc := svcImpl.NewOrgService(dbPool, orgDao, sectorDao)
-	sectorSvc := svcImpl.NewSectorService(sectorDao)
+	tenantDao := impl.NewTenantDao(dbPool, kSql)
+	templateDao := impl.NewTemplateDao(dbPool, kSql)
+	sectorSchemaDao := impl.NewSectorSchemaDao(dbPool, kSql)
+	orgSvc := svcImpl.NewOrgService(dbPool, orgDao, sectorDao, userDao, templateDao, sectorSchemaDao)
+	sectorSvc := svcImpl.NewSectorService(dbPool, sectorDao, userDao, sectorSchemaDao)
 	userSvc := svcImpl.NewUserService(userDao)
+	tenantSvc := svcImpl.NewTenantService(tenantDao)
+	templateSvc := svcImpl.NewTemplateService(templateDao)
+	adminSvc := svcImpl.NewAdminService(dbPool, orgDao, sectorDao, userDao)
 
 	var defErrorHandler = func(c *fiber.Ctx, err error) error {
 		var e *fiber.Error
@@ -111,29 +123,82 @@ func main() {
 
 	app.Static("/", "./static")
 
+	// Tenants administration
+	app.Use(TenantsV1Root, basicauth.New(middlewares.NewBasicAuthConfig(configuration.BasicAuthUser, configuration.BasicAuthPass)))
+	app.Get(TenantsV1Root, endpoints.MakeTenantFindAll(tenantSvc))
+	app.Post(TenantsV1Root, endpoints.MakeTenantCreateEndpoint(tenantSvc))
+	app.Get(TenantsV1TenantCode, endpoints.MakeTenantFindByCode(tenantSvc))
+	app.Put(TenantsV1TenantCode, endpoints.MakeTenantRenameEndpoint(tenantSvc))
+	app.Delete(TenantsV1TenantCode, endpoints.MakeTenantDeleteEndpoint(tenantSvc))
+	app.Post(TenantsV1TenantCode+"/suspend", endpoints.MakeTenantSuspendEndpoint(tenantSvc))
+	app.Post(TenantsV1TenantCode+"/activate", endpoints.MakeTenantActivateEndpoint(tenantSvc))
+
+	// Administration
+	app.Use(AdminV1Root, basicauth.New(middlewares.NewBasicAuthConfig(configuration.BasicAuthUser, configuration.BasicAuthPass)))
+	app.Post(AdminV1Root+"/purge", endpoints.MakePurgeEndpoint(configuration.PurgeRetentionDays, adminSvc))
+
+	// Tenant resolution for tenant scoped resources
+	tenantResolver := middlewares.NewTenantResolver(configuration, store, tenantSvc)
+	app.Use(OrgV1Root, tenantResolver)
+	app.Use(TemplatesV1Root, tenantResolver)
+
+	// Sector templates
+	app.Get(TemplatesV1Root, endpoints.MakeTemplateFindAll(templateSvc))
+	app.Post(TemplatesV1Root, endpoints.MakeTemplateCreateEndpoint(templateSvc))
+	app.Get(TemplatesV1TemplateCode, endpoints.MakeTemplateFindByCode(templateSvc))
+	app.Delete(TemplatesV1TemplateCode, endpoints.MakeTemplateDeleteEndpoint(templateSvc))
+
 	// Organizations
-	app.Get(OrgV1Root, endpoints.MakeOrgFindAll(configuration.TenantId, orgSvc))
-	app.Post(OrgV1Root, endpoints.MakeOrgCreateEndpoint(configuration.RdbmsUrl, configuration.TenantId, orgSvc))
-	app.Put(OrgV1OrgCode, endpoints.MakeOrgUpdateEndpoint(configuration.TenantId, orgSvc))
-	app.Delete(OrgV1OrgCode, endpoints.MakeOrgDeleteEndpoint(configuration.TenantId, orgSvc))
-	app.Get(OrgV1OrgCode, endpoints.MakeOrgFindByCodeEndpoint(configuration.TenantId, orgSvc))
+	app.Get(OrgV1Root, endpoints.MakeOrgFindAll(orgSvc))
+	app.Post(OrgV1Root, endpoints.MakeOrgCreateEndpoint(configuration.RdbmsUrl, orgSvc))
+	app.Get(OrgV1Root+"/tree", endpoints.MakeOrgTreeEndpoint(orgSvc))
+	app.Put(OrgV1OrgCode, endpoints.MakeOrgUpdateEndpoint(orgSvc))
+	app.Patch(OrgV1OrgCode, endpoints.MakeOrgPatchEndpoint(orgSvc))
+	app.Delete(OrgV1OrgCode, endpoints.MakeOrgDeleteEndpoint(orgSvc))
+	app.Get(OrgV1OrgCode, endpoints.MakeOrgFindByCodeEndpoint(orgSvc))
+	app.Get(OrgV1OrgCode+"/children", endpoints.MakeOrgChildrenEndpoint(orgSvc))
+	app.Post(OrgV1OrgCode+"/activate", endpoints.MakeOrgLifecycleEndpoint(model.OrgActionActivate, orgSvc))
+	app.Post(OrgV1OrgCode+"/deactivate", endpoints.MakeOrgLifecycleEndpoint(model.OrgActionDeactivate, orgSvc))
+	app.Post(OrgV1OrgCode+"/archive", endpoints.MakeOrgLifecycleEndpoint(model.OrgActionArchive, orgSvc))
+	app.Post(OrgV1OrgCode+"/restore", endpoints.MakeOrgLifecycleEndpoint(model.OrgActionRestore, orgSvc))
+	app.Post(OrgV1OrgCode+"/clone", endpoints.MakeOrgCloneEndpoint(orgSvc))
 
 	// Sectors
-	app.Get(SectorsV1Root, endpoints.MakeSectorsFindByOrga(configuration.TenantId, orgSvc, sectorSvc))
-	app.Post(SectorsV1Root, endpoints.MakeSectorCreateEndpoint(configuration.TenantId, orgSvc, sectorSvc))
-	app.Put(SectorsV1SectorCode, endpoints.MakeSectorUpdateEndpoint(configuration.TenantId, orgSvc, sectorSvc))
-	app.Delete(SectorsV1SectorCode, endpoints.MakeSectorDeleteEndpoint(configuration.TenantId, orgSvc, sectorSvc))
+	app.Get(OrgV1OrgCode+"/sector-schema", endpoints.MakeSectorSchemaFind(orgSvc, sectorSvc))
+	app.Put(OrgV1OrgCode+"/sector-schema", endpoints.MakeSectorSchemaSave(orgSvc, sectorSvc))
+	app.Get(SectorsV1Root, endpoints.MakeSectorsFindByOrga(orgSvc, sectorSvc))
+	app.Post(SectorsV1Root, endpoints.MakeSectorCreateEndpoint(orgSvc, sectorSvc))
+	app.Post(SectorsV1Root+"/tree", endpoints.MakeSectorTreeCreateEndpoint(orgSvc, sectorSvc))
+	app.Get(SectorsV1Root+"/by-path", endpoints.MakeSectorFindByPath(orgSvc, sectorSvc))
+	app.Get(SectorsV1Root+"/diff", endpoints.MakeSectorDiffEndpoint(orgSvc, sectorSvc))
+	app.Post(SectorsV1Root+"/diff", endpoints.MakeSectorDiffEndpoint(orgSvc, sectorSvc))
+	app.Get(SectorsV1Root+"/export", endpoints.MakeSectorExportEndpoint(orgSvc, sectorSvc))
+	app.Post(SectorsV1Root+"/import", endpoints.MakeSectorImportEndpoint(orgSvc, sectorSvc))
+	app.Get(SectorsV1SectorCode, endpoints.MakeSectorFindByCode(orgSvc, sectorSvc))
+	app.Get(SectorsV1SectorCode+"/descendants", endpoints.MakeSectorDescendants(orgSvc, sectorSvc))
+	app.Put(SectorsV1SectorCode, endpoints.MakeSectorUpdateEndpoint(orgSvc, sectorSvc))
+	app.Delete(SectorsV1SectorCode, endpoints.MakeSectorDeleteEndpoint(orgSvc, sectorSvc))
+	app.Post(SectorsV1SectorCode+"/restore", endpoints.MakeSectorRestoreEndpoint(orgSvc, sectorSvc))
+	app.Post(SectorsV1SectorCode+"/move", endpoints.MakeSectorMoveEndpoint(orgSvc, sectorSvc))
+	app.Post(SectorsV1SectorCode+"/merge", endpoints.MakeSectorMergeEndpoint(orgSvc, sectorSvc))
+	app.Post(SectorsV1SectorCode+"/split", endpoints.MakeSectorSplitEndpoint(orgSvc, sectorSvc))
+	app.Post(SectorsV1SectorCode+"/activate", endpoints.MakeSectorStatusEndpoint(model.SectorStatusActive, orgSvc, sectorSvc))
+	app.Post(SectorsV1SectorCode+"/deactivate", endpoints.MakeSectorStatusEndpoint(model.SectorStatusInactive, orgSvc, sectorSvc))
+	app.Put(SectorsV1SectorCode+"/children/order", endpoints.MakeSectorReorderEndpoint(orgSvc, sectorSvc))
+	app.Put(SectorsV1SectorCode+"/users/:userId", endpoints.MakeSectorMemberEndpoint(true, orgSvc, sectorSvc))
+	app.Delete(SectorsV1SectorCode+"/users/:userId", endpoints.MakeSectorMemberEndpoint(false, orgSvc, sectorSvc))
 
 	// Users
-	app.Get(UsersV1Root, endpoints.MakeUserSearchFilter(configuration.TenantId, userSvc, orgSvc))
-	app.Get(UsersV1UserId, endpoints.MakeUserFindByCode(configuration.TenantId, userSvc, orgSvc))
-	app.Post(UsersV1Root, endpoints.MakeUserCreateEndpoint(configuration.TenantId, userSvc, orgSvc))
-	app.Put(UsersV1UserId, endpoints.MakeUserUpdate(configuration.TenantId, userSvc, orgSvc))
-	app.Delete(UsersV1UserId, endpoints.MakeUserDelete(configuration.TenantId, userSvc, orgSvc))
+	app.Get(UsersV1Root, endpoints.MakeUserSearchFilter(userSvc, orgSvc))
+	app.Get(UsersV1UserId, endpoints.MakeUserFindByCode(userSvc, orgSvc))
+	app.Post(UsersV1Root, endpoints.MakeUserCreateEndpoint(userSvc, orgSvc))
+	app.Put(UsersV1UserId, endpoints.MakeUserUpdate(userSvc, orgSvc))
+	app.Delete(UsersV1UserId, endpoints.MakeUserDelete(userSvc, orgSvc))
+	app.Post(UsersV1UserId+"/restore", endpoints.MakeUserRestore(userSvc, orgSvc))
 
 	// OAuth and authentication
 	app.Get("/api/v1/authenticate", endpoints.MakeGitlabAuthentication(store, configuration.OAuthGithub, configuration.OAuthClientId, configuration.OAuthRedirectUri))
-	app.Get("/oauth/redirect", endpoints.MakeOAuthAuthorize(store, configuration.OAuthCallbackUrl, configuration.OAuthClientId, configuration.OAuthClientSecret, configuration.OAuthDebug, configuration.GithubUserInfos))
+	app.Get("/oauth/redirect", endpoints.MakeOAuthAuthorize(store, tenantSvc, configuration.OAuthCallbackUrl, configuration.OAuthClientId, configuration.OAuthClientSecret, configuration.OAuthDebug, configuration.GithubUserInfos))
 
 	go func() {
 		stdLogger.Info("Application -> Listen TLS")
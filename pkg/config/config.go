@@ -13,6 +13,8 @@ import (
 type Configuration struct {
 	ServerPort            string
 	TenantId              int64
+	MultiTenant           bool
+	TenantSubdomain       bool
 	GithubUserInfos       string
 	LogsMetrics           string
 	LogsStd               string
@@ -33,6 +35,7 @@ type Configuration struct {
 	PrometheusEnabled     bool
 	BasicAuthUser         string
 	BasicAuthPass         string
+	PurgeRetentionDays    int
 }
 
 func LoadConfigFile(configPath string) *Configuration {
@@ -44,6 +47,8 @@ func LoadConfigFile(configPath string) *Configuration {
 	config := Configuration{
 		ServerPort:            kConfig.String("http.server.port"),
 		TenantId:              kConfig.Int64("app.tenant"),
+		MultiTenant:           kConfig.Bool("app.multiTenant"),
+		TenantSubdomain:       kConfig.Bool("app.tenantSubdomain"),
 		LogsMetrics:           kConfig.String("app.accessLogFile"),
 		LogsStd:               kConfig.String("app.stdLogFile"),
 		OAuthCallbackUrl:      kConfig.String("app.oauthCallback"),
@@ -64,6 +69,7 @@ func LoadConfigFile(configPath string) *Configuration {
 		BasicAuthUser:         kConfig.String("app.basicAuthUser"),
 		BasicAuthPass:         kConfig.String("app.basicAuthPass"),
 		GithubUserInfos:       kConfig.String("app.githubUserInfos"),
+		PurgeRetentionDays:    kConfig.Int("app.purgeRetentionDays"),
 	}
 	return &config
 }
@@ -1,6 +1,7 @@
 package converters
 
 import (
+	"database/sql"
 	"micro-fiber-test/pkg/dto/orgs"
 	"micro-fiber-test/pkg/model"
 )
@@ -14,15 +15,30 @@ func ConvertOrgReqToDaoModel(defaultTenantId int64, orgReq orgs.CreateOrgRequest
 	if orgReq.Kind != nil {
 		org.Type = model.OrganizationType(*orgReq.Kind)
 	}
-	org.Status = model.OrganizationStatus(orgReq.Status)
+	org.Status = model.OrganizationStatus(*orgReq.Status)
+	if orgReq.Parent != nil && *orgReq.Parent != "" {
+		org.ParentCode = sql.NullString{String: *orgReq.Parent, Valid: true}
+	}
 	return org
 }
 
 func ConvertOrgModelToOrgResp(org model.Organization) orgs.OrganizationResponse {
 	return orgs.OrganizationResponse{
-		Code:   org.Code,
-		Label:  org.Label,
-		Status: int(org.Status),
-		Kind:   string(org.Type),
+		Code:       org.Code,
+		Label:      org.Label,
+		Status:     int(org.Status),
+		Kind:       string(org.Type),
+		ParentCode: org.ParentCode.String,
+		Version:    org.Version,
+	}
+}
+
+func ConvertOrgModelToOrgTreeResp(org model.Organization) orgs.OrgTreeResponse {
+	return orgs.OrgTreeResponse{
+		Code:       org.Code,
+		Label:      org.Label,
+		Status:     int(org.Status),
+		Kind:       string(org.Type),
+		ParentCode: org.ParentCode.String,
 	}
 }
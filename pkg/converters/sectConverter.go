@@ -7,14 +7,112 @@ import (
 
 func ConvertSectorModelToSectorResp(sect model.Sector) sectors.SectorResponse {
 	sectorResponse := sectors.SectorResponse{
-		Id:     sect.Id,
-		Code:   sect.Code,
-		Label:  sect.Label,
-		Depth:  sect.Depth,
-		Status: sect.Status,
+		Id:         sect.Id,
+		Code:       sect.Code,
+		Label:      sect.Label,
+		Depth:      sect.Depth,
+		Position:   sect.Position,
+		Status:     sect.Status,
+		Version:    sect.Version,
+		Attributes: sect.Attributes,
 	}
 	if sect.HasParent {
 		sectorResponse.ParentId = sect.ParentId.Int64
 	}
 	return sectorResponse
 }
+
+func ConvertSectorModelToBreadcrumb(sect model.Sector) sectors.SectorBreadcrumb {
+	return sectors.SectorBreadcrumb{
+		Code:  sect.Code,
+		Label: sect.Label,
+		Depth: sect.Depth,
+	}
+}
+
+func ConvertSectorModelToFlatResp(sect model.Sector, parentCode string) sectors.SectorFlatResponse {
+	return sectors.SectorFlatResponse{
+		Code:       sect.Code,
+		Label:      sect.Label,
+		ParentCode: parentCode,
+		Depth:      sect.Depth,
+		Position:   sect.Position,
+		Status:     sect.Status,
+		Version:    sect.Version,
+		Attributes: sect.Attributes,
+	}
+}
+
+func ConvertSectorTreeReqToModel(nodesReq []sectors.SectorTreeNodeReq) []model.SectorTreeNode {
+	nodes := make([]model.SectorTreeNode, len(nodesReq))
+	for inc, nodeReq := range nodesReq {
+		nodes[inc] = model.SectorTreeNode{
+			Label:      nodeReq.Label,
+			Status:     model.SectorStatus(nodeReq.Status),
+			Attributes: nodeReq.Attributes,
+			Children:   ConvertSectorTreeReqToModel(nodeReq.Children),
+		}
+	}
+	return nodes
+}
+
+func ConvertSectorTreeModelToResp(nodes []model.SectorTreeNode) []sectors.SectorTreeResponse {
+	responses := make([]sectors.SectorTreeResponse, len(nodes))
+	for inc, node := range nodes {
+		responses[inc] = sectors.SectorTreeResponse{
+			Code:     node.Code,
+			Label:    node.Label,
+			Children: ConvertSectorTreeModelToResp(node.Children),
+		}
+	}
+	return responses
+}
+
+func ConvertSectorSchemaDtoToModel(schemaDto sectors.SectorSchemaDto) []model.SectorAttributeDef {
+	defs := make([]model.SectorAttributeDef, len(schemaDto.Attributes))
+	for inc, defDto := range schemaDto.Attributes {
+		defs[inc] = model.SectorAttributeDef{
+			Name:          defDto.Name,
+			Type:          model.SectorAttributeType(defDto.Type),
+			Required:      defDto.Required,
+			AllowedValues: defDto.AllowedValues,
+		}
+	}
+	return defs
+}
+
+func ConvertSectorSchemaModelToDto(defs []model.SectorAttributeDef) sectors.SectorSchemaDto {
+	defDtos := make([]sectors.SectorAttributeDefDto, len(defs))
+	for inc, def := range defs {
+		defDtos[inc] = sectors.SectorAttributeDefDto{
+			Name:          def.Name,
+			Type:          string(def.Type),
+			Required:      def.Required,
+			AllowedValues: def.AllowedValues,
+		}
+	}
+	return sectors.SectorSchemaDto{Attributes: defDtos}
+}
+
+func ConvertSectorDiffModelToResp(diff model.SectorTreeDiff) sectors.SectorDiffResponse {
+	convert := func(entries []model.SectorDiffEntry) []sectors.SectorDiffEntryResponse {
+		entriesResp := make([]sectors.SectorDiffEntryResponse, len(entries))
+		for inc, entry := range entries {
+			entriesResp[inc] = sectors.SectorDiffEntryResponse{
+				Code:               entry.Code,
+				TargetCode:         entry.TargetCode,
+				Label:              entry.Label,
+				PreviousLabel:      entry.PreviousLabel,
+				ParentCode:         entry.ParentCode,
+				PreviousParentCode: entry.PreviousParentCode,
+			}
+		}
+		return entriesResp
+	}
+	return sectors.SectorDiffResponse{
+		Added:   convert(diff.Added),
+		Removed: convert(diff.Removed),
+		Renamed: convert(diff.Renamed),
+		Moved:   convert(diff.Moved),
+	}
+}
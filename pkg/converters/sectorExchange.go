@@ -0,0 +1,132 @@
+package converters
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/dto/sectors"
+	"micro-fiber-test/pkg/model"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var sectorCsvHeader = []string{"code", "label", "parentCode", "depth", "status", "attributes"}
+
+// WriteSectorsCsv writes the sectors one per line, parents before their children, attributes being written as a JSON object
+func WriteSectorsCsv(w io.Writer, sectorsList []model.Sector) error {
+	codes := make(map[int64]string, len(sectorsList))
+	for _, sector := range sectorsList {
+		codes[sector.Id] = sector.Code
+	}
+	ordered := make([]model.Sector, len(sectorsList))
+	copy(ordered, sectorsList)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Depth < ordered[j].Depth
+	})
+
+	csvWriter := csv.NewWriter(w)
+	if errWrite := csvWriter.Write(sectorCsvHeader); errWrite != nil {
+		return errWrite
+	}
+	for _, sector := range ordered {
+		parentCode := ""
+		if sector.HasParent {
+			parentCode = codes[sector.ParentId.Int64]
+		}
+		attributes := ""
+		if len(sector.Attributes) > 0 {
+			encoded, errEncode := json.Marshal(sector.Attributes)
+			if errEncode != nil {
+				return errEncode
+			}
+			attributes = string(encoded)
+		}
+		record := []string{sector.Code, sector.Label, parentCode, strconv.Itoa(sector.Depth), strconv.FormatInt(int64(sector.Status), 10), attributes}
+		if errWrite := csvWriter.Write(record); errWrite != nil {
+			return errWrite
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// ReadSectorsCsv reads the rows of a file written by WriteSectorsCsv, columns are found by their header name and depth is ignored.
+// The attributes column is optional, an empty cell keeps the attributes of an existing sector.
+func ReadSectorsCsv(r io.Reader) ([]model.SectorImportRow, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = -1
+	header, errHeader := csvReader.Read()
+	if errHeader != nil {
+		return nil, errors.New(commons.SectorImportBadFormat)
+	}
+	columns := make(map[string]int, len(header))
+	for inc, name := range header {
+		columns[strings.TrimSpace(name)] = inc
+	}
+	for _, name := range []string{"code", "label", "parentCode", "status"} {
+		if _, found := columns[name]; !found {
+			return nil, errors.New(commons.SectorImportBadFormat)
+		}
+	}
+	column := func(record []string, name string) string {
+		if _, found := columns[name]; !found || columns[name] >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[columns[name]])
+	}
+
+	var rows []model.SectorImportRow
+	for {
+		record, errRead := csvReader.Read()
+		if errors.Is(errRead, io.EOF) {
+			break
+		}
+		if errRead != nil {
+			return nil, errors.New(commons.SectorImportBadFormat)
+		}
+		line, _ := csvReader.FieldPos(0)
+		status, errStatus := strconv.Atoi(column(record, "status"))
+		if errStatus != nil {
+			status = -1
+		}
+		var attributes map[string]any
+		if encoded := column(record, "attributes"); encoded != "" {
+			if errDecode := json.Unmarshal([]byte(encoded), &attributes); errDecode != nil {
+				return nil, errors.New(commons.SectorImportBadFormat)
+			}
+		}
+		rows = append(rows, model.SectorImportRow{
+			Line:       line,
+			Code:       column(record, "code"),
+			Label:      column(record, "label"),
+			ParentCode: column(record, "parentCode"),
+			Status:     model.SectorStatus(status),
+			Attributes: attributes,
+		})
+	}
+	return rows, nil
+}
+
+// ConvertSectorTreeToImportRows flattens a nested tree, each row's line being its rank in depth first order
+func ConvertSectorTreeToImportRows(root sectors.SectorResponse) []model.SectorImportRow {
+	var rows []model.SectorImportRow
+	var flatten func(node sectors.SectorResponse, parentCode string)
+	flatten = func(node sectors.SectorResponse, parentCode string) {
+		rows = append(rows, model.SectorImportRow{
+			Line:       len(rows) + 1,
+			Code:       node.Code,
+			Label:      node.Label,
+			ParentCode: parentCode,
+			Status:     node.Status,
+			Attributes: node.Attributes,
+		})
+		for _, child := range node.Children {
+			flatten(child, node.Code)
+		}
+	}
+	flatten(root, "")
+	return rows
+}
@@ -0,0 +1,39 @@
+package converters
+
+import (
+	"micro-fiber-test/pkg/dto/templates"
+	"micro-fiber-test/pkg/model"
+)
+
+func ConvertTemplateReqToDaoModel(tenantId int64, templateReq templates.CreateTemplateReq) model.SectorTemplate {
+	template := model.SectorTemplate{TenantId: tenantId}
+	if templateReq.Label != nil {
+		template.Label = *templateReq.Label
+	}
+	template.Nodes = convertTemplateNodesReq(templateReq.Sectors)
+	return template
+}
+
+func convertTemplateNodesReq(nodesReq []templates.TemplateNodeReq) []model.SectorTemplateNode {
+	nodes := make([]model.SectorTemplateNode, len(nodesReq))
+	for inc, nodeReq := range nodesReq {
+		nodes[inc] = model.SectorTemplateNode{Label: nodeReq.Label, Attributes: nodeReq.Attributes, Children: convertTemplateNodesReq(nodeReq.Children)}
+	}
+	return nodes
+}
+
+func ConvertTemplateModelToTemplateResp(template model.SectorTemplate) templates.TemplateResponse {
+	return templates.TemplateResponse{
+		Code:    template.Code,
+		Label:   template.Label,
+		Sectors: convertTemplateNodesModel(template.Nodes),
+	}
+}
+
+func convertTemplateNodesModel(nodes []model.SectorTemplateNode) []templates.TemplateNodeResponse {
+	nodesResp := make([]templates.TemplateNodeResponse, len(nodes))
+	for inc, node := range nodes {
+		nodesResp[inc] = templates.TemplateNodeResponse{Label: node.Label, Attributes: node.Attributes, Children: convertTemplateNodesModel(node.Children)}
+	}
+	return nodesResp
+}
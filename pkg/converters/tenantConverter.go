@@ -0,0 +1,25 @@
+package converters
+
+import (
+	"micro-fiber-test/pkg/dto/tenants"
+	"micro-fiber-test/pkg/model"
+)
+
+func ConvertTenantReqToDaoModel(tenantReq tenants.CreateTenantReq) model.Tenant {
+	tenant := model.Tenant{}
+	if tenantReq.Code != nil {
+		tenant.Code = *tenantReq.Code
+	}
+	if tenantReq.Label != nil {
+		tenant.Label = *tenantReq.Label
+	}
+	return tenant
+}
+
+func ConvertTenantModelToTenantResp(tenant model.Tenant) tenants.TenantResponse {
+	return tenants.TenantResponse{
+		Code:   tenant.Code,
+		Label:  tenant.Label,
+		Status: int(tenant.Status),
+	}
+}
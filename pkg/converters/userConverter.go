@@ -41,5 +41,6 @@ func ConvertFromDaoModelToUserResponse(userInterface model.User) users.UserRespo
 	usr.FirstName = userInterface.FirstName
 	usr.MiddleName = userInterface.MiddleName
 	usr.Status = int(userInterface.Status)
+	usr.Version = userInterface.Version
 	return usr
 }
@@ -0,0 +1,8 @@
+package admin
+
+type PurgeResponse struct {
+	RetentionDays int   `json:"retentionDays"`
+	Users         int64 `json:"users"`
+	Sectors       int64 `json:"sectors"`
+	Organizations int64 `json:"organizations"`
+}
@@ -5,13 +5,75 @@ const (
 	OrgAlreadyExistsByLabel = "org_already_label"
 	OrgDoesNotExistByCode   = "org_does_not_exist"
 	OrgNotFound             = "org_not_found"
+	OrgIllegalTransition    = "org_illegal_status_transition"
+	OrgInvalidType          = "org_invalid_type"
+	OrgInvalidPayload       = "org_invalid_payload"
+	OrgNotActive            = "org_not_active"
+	OrgHasUsers             = "org_has_users"
+	OrgInvalidStrategy      = "org_invalid_delete_strategy"
+	OrgMoveTargetInvalid    = "org_move_target_invalid"
+	OrgMoveTargetNotFound   = "org_move_target_not_found"
+	OrgParentNotFound       = "org_parent_not_found"
+	OrgParentCycle          = "org_parent_cycle"
+	OrgParentTypeInvalid    = "org_parent_type_invalid"
+	OrgHasChildren          = "org_has_children"
+	OrgCloneMembersInvalid  = "org_clone_members_invalid"
 	SectorAlreadyExist      = "sector_already_exists"
 	SectorRootNotFound      = "sector_root_not_found"
 	SectorNotFound          = "sector_not_found"
+	SectorParentDeleted     = "sector_parent_deleted"
+	SectorParentNotFound    = "sector_parent_not_found"
+	SectorMoveRoot          = "sector_move_root"
+	SectorMoveCycle         = "sector_move_cycle"
+	SectorMoveCrossOrg      = "sector_move_cross_org"
+	SectorDeleteRoot        = "sector_delete_root"
+	SectorInvalidDeleteMode = "sector_invalid_delete_mode"
+	SectorReorderInvalid    = "sector_reorder_invalid"
+	SectorParentInactive    = "sector_parent_inactive"
+	SectorHasActiveMembers  = "sector_has_active_members"
+	SectorHierarchyInvalid  = "sector_hierarchy_invalid"
+	SectorPathInvalid       = "sector_path_invalid"
+	SectorPathNotFound      = "sector_path_not_found"
+	SectorParentAmbiguous   = "sector_parent_ambiguous"
+	SectorImportInvalid     = "sector_import_invalid"
+	SectorImportBadFormat   = "sector_import_bad_format"
+	SectorImportNoCode      = "sector_import_missing_code"
+	SectorImportDupCode     = "sector_import_duplicate_code"
+	SectorImportCodeTaken   = "sector_import_code_taken"
+	SectorImportBadLabel    = "sector_import_invalid_label"
+	SectorImportBadStatus   = "sector_import_invalid_status"
+	SectorImportNoParent    = "sector_import_unknown_parent"
+	SectorImportCycle       = "sector_import_cycle"
+	SectorImportDupLabel    = "sector_import_duplicate_label"
+	SectorImportRootChange  = "sector_import_root_change"
+	SectorImportBadAttrs    = "sector_import_invalid_attributes"
+	SectorAttributesInvalid = "sector_attributes_invalid"
+	SectorDiffBadFormat     = "sector_diff_bad_format"
+	SectorTargetNotFound    = "sector_target_not_found"
+	SectorMergeRoot         = "sector_merge_root"
+	SectorMergeCycle        = "sector_merge_cycle"
+	SectorSplitRoot         = "sector_split_root"
+	SectorSplitInvalid      = "sector_split_invalid"
+	SectorOrphan            = "sector_orphan"
+	SectorMultipleRoots     = "sector_multiple_roots"
+	SectorDepthMismatch     = "sector_depth_mismatch"
+	SectorUnreachable       = "sector_unreachable"
 	UserNotFound            = "user_not_found"
 	UserLoginAlreadyInUse   = "user_login_already_in_use"
 	UserEmailAlreadyInUse   = "user_email_already_in_use"
 	OAuthStateMismatch      = "oauth_state_mismatch"
+	VersionMismatch         = "version_mismatch"
+	PurgeInvalidRetention   = "purge_invalid_retention"
+	TenantNotFound          = "tenant_not_found"
+	TenantInactive          = "tenant_inactive"
+	TenantNotResolved       = "tenant_not_resolved"
+	TenantMismatch          = "tenant_mismatch"
+	TenantSuspended         = "tenant_suspended"
+	TenantAlreadyExists     = "tenant_already_exists"
+	TenantNotEmpty          = "tenant_not_empty"
+	TemplateNotFound        = "template_not_found"
+	TemplateAlreadyExists   = "template_already_exists"
+	TemplateInvalidPayload  = "template_invalid_payload"
 )
 
 type ApiErrorType string
@@ -0,0 +1,6 @@
+package orgs
+
+type CloneOrgRequest struct {
+	Label       *string `json:"label" validate:"required,max=50"`
+	CopyMembers bool    `json:"copyMembers"`
+}
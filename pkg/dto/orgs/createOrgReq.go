@@ -1,7 +1,9 @@
 package orgs
 
 type CreateOrgRequest struct {
-	Label  *string `json:"label" validate:"required,max=50"`
-	Kind   *string `json:"type" validate:"required"`
-	Status int     `json:"status" validate:"required"`
+	Label    *string `json:"label" validate:"required,max=50"`
+	Kind     *string `json:"type" validate:"required"`
+	Status   *int    `json:"status" validate:"required"`
+	Template *string `json:"template"`
+	Parent   *string `json:"parent"`
 }
@@ -3,9 +3,12 @@ package orgs
 import "micro-fiber-test/pkg/model"
 
 type CreateSectorReq struct {
-	Label      *string `json:"label" validate:"required,max=50"`
-	ParentCode string  `json:"parentCode"`
-	Status     int     `json:"status"`
+	Label      *string        `json:"label" validate:"required,max=50"`
+	ParentCode string         `json:"parentCode"`
+	ParentPath string         `json:"parentPath"`
+	Status     int            `json:"status" validate:"oneof=0 1 2"`
+	Position   *int           `json:"position" validate:"omitempty,min=0"`
+	Attributes map[string]any `json:"attributes"`
 }
 
 func ConvertSectorReqToDaoModel(defaultTenantId int64, sectorReq CreateSectorReq) model.Sector {
@@ -15,5 +18,10 @@ func ConvertSectorReqToDaoModel(defaultTenantId int64, sectorReq CreateSectorReq
 		sect.Label = *sectorReq.Label
 	}
 	sect.Status = model.SectorStatus(sectorReq.Status)
+	sect.Attributes = sectorReq.Attributes
+	sect.Position = model.SectorPositionLast
+	if sectorReq.Position != nil {
+		sect.Position = *sectorReq.Position
+	}
 	return sect
 }
@@ -0,0 +1,11 @@
+package orgs
+
+type OrgDeletionResponse struct {
+	Strategy  string `json:"strategy"`
+	TargetOrg string `json:"targetOrg,omitempty"`
+	DryRun    bool   `json:"dryRun"`
+	Users     int64  `json:"users"`
+	Sectors   int64  `json:"sectors"`
+	Children  int64  `json:"children"`
+	Refused   bool   `json:"refused"`
+}
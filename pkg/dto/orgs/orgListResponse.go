@@ -1,12 +1,17 @@
 package orgs
 
+import "micro-fiber-test/pkg/dto/commons"
+
 type OrganizationListResponse struct {
+	Pagination    commons.Pagination     `json:"pagination,omitempty"`
 	Organizations []OrganizationResponse `json:"organizations,omitempty"`
 }
 
 type OrganizationResponse struct {
-	Code   string `json:"code"`
-	Label  string `json:"label"`
-	Kind   string `json:"type"`
-	Status int    `json:"status"`
+	Code       string `json:"code"`
+	Label      string `json:"label"`
+	Kind       string `json:"type"`
+	Status     int    `json:"status"`
+	ParentCode string `json:"parentCode,omitempty"`
+	Version    int64  `json:"version"`
 }
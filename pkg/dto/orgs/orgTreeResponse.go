@@ -0,0 +1,14 @@
+package orgs
+
+type OrgTreeResponse struct {
+	Code       string            `json:"code"`
+	Label      string            `json:"label"`
+	Kind       string            `json:"type"`
+	Status     int               `json:"status"`
+	ParentCode string            `json:"parentCode,omitempty"`
+	Children   []OrgTreeResponse `json:"children,omitempty"`
+}
+
+type OrgTreeListResponse struct {
+	Organizations []OrgTreeResponse `json:"organizations,omitempty"`
+}
@@ -0,0 +1,65 @@
+package orgs
+
+import (
+	"fmt"
+	"micro-fiber-test/pkg/model"
+	"micro-fiber-test/pkg/validation"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// ParseOrgMergePatch decodes a JSON Merge Patch (RFC 7396) document targeting an organization.
+// Members set to null would remove mandatory attributes and are reported as validation errors, except parent which detaches the organization.
+func ParseOrgMergePatch(body []byte) (model.OrganizationPatch, []validation.ErrorValidation, error) {
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	patch := model.OrganizationPatch{}
+	var members map[string]jsoniter.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil {
+		return patch, nil, err
+	}
+
+	var errorsList []validation.ErrorValidation
+	for name, raw := range members {
+		if name == "parent" && string(raw) == "null" {
+			detach := ""
+			patch.ParentCode = &detach
+			continue
+		}
+		if string(raw) == "null" {
+			errorsList = append(errorsList, validation.ErrorValidation{Field: name, Error: fmt.Sprintf(validation.FieldNotRemovable, name)})
+			continue
+		}
+		var errMember error
+		switch name {
+		case "label":
+			var label string
+			errMember = json.Unmarshal(raw, &label)
+			patch.Label = &label
+			if errMember == nil && label == "" {
+				errorsList = append(errorsList, validation.ErrorValidation{Field: name, Error: fmt.Sprintf(validation.FieldRequired, name)})
+			} else if len(label) > 50 {
+				errorsList = append(errorsList, validation.ErrorValidation{Field: name, Error: fmt.Sprintf(validation.FieldMaxLength, name, "50")})
+			}
+		case "type":
+			var kind string
+			errMember = json.Unmarshal(raw, &kind)
+			orgType := model.OrganizationType(kind)
+			patch.Type = &orgType
+		case "status":
+			var status int
+			errMember = json.Unmarshal(raw, &status)
+			orgStatus := model.OrganizationStatus(status)
+			patch.Status = &orgStatus
+		case "parent":
+			var parentCode string
+			errMember = json.Unmarshal(raw, &parentCode)
+			patch.ParentCode = &parentCode
+		default:
+			errorsList = append(errorsList, validation.ErrorValidation{Field: name, Error: fmt.Sprintf(validation.FieldUnknown, name)})
+		}
+		if errMember != nil {
+			errorsList = append(errorsList, validation.ErrorValidation{Field: name, Error: fmt.Sprintf(validation.FieldInvalidFormat, name)})
+		}
+	}
+	return patch, errorsList, nil
+}
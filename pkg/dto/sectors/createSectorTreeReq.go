@@ -0,0 +1,42 @@
+package sectors
+
+import (
+	"fmt"
+	"micro-fiber-test/pkg/validation"
+)
+
+type CreateSectorTreeReq struct {
+	ParentCode string              `json:"parentCode"`
+	ParentPath string              `json:"parentPath"`
+	Sectors    []SectorTreeNodeReq `json:"sectors" validate:"required,min=1"`
+}
+
+type SectorTreeNodeReq struct {
+	Label      string              `json:"label"`
+	Status     int                 `json:"status"`
+	Attributes map[string]any      `json:"attributes"`
+	Children   []SectorTreeNodeReq `json:"children"`
+}
+
+// ValidateSectorTreeNodes checks every node of a sector tree, fields are reported with their path (sectors[0].children[1].label)
+func ValidateSectorTreeNodes(prefix string, nodes []SectorTreeNodeReq) []validation.ErrorValidation {
+	var errorsList []validation.ErrorValidation
+	labels := make(map[string]bool, len(nodes))
+	for inc, node := range nodes {
+		field := fmt.Sprintf("%s[%d].label", prefix, inc)
+		if node.Label == "" {
+			errorsList = append(errorsList, validation.ErrorValidation{Field: field, Error: fmt.Sprintf(validation.FieldRequired, field)})
+		} else if len(node.Label) > 50 {
+			errorsList = append(errorsList, validation.ErrorValidation{Field: field, Error: fmt.Sprintf(validation.FieldMaxLength, field, "50")})
+		} else if labels[node.Label] {
+			errorsList = append(errorsList, validation.ErrorValidation{Field: field, Error: fmt.Sprintf(validation.FieldInvalidValue, field, node.Label)})
+		}
+		labels[node.Label] = true
+		if node.Status < 0 || node.Status > 2 {
+			statusField := fmt.Sprintf("%s[%d].status", prefix, inc)
+			errorsList = append(errorsList, validation.ErrorValidation{Field: statusField, Error: fmt.Sprintf(validation.FieldInvalidValue, statusField, fmt.Sprint(node.Status))})
+		}
+		errorsList = append(errorsList, ValidateSectorTreeNodes(fmt.Sprintf("%s[%d].children", prefix, inc), node.Children)...)
+	}
+	return errorsList
+}
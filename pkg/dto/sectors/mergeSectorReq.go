@@ -0,0 +1,5 @@
+package sectors
+
+type MergeSectorReq struct {
+	TargetCode *string `json:"targetCode" validate:"required"`
+}
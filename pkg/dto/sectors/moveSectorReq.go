@@ -0,0 +1,5 @@
+package sectors
+
+type MoveSectorReq struct {
+	ParentCode *string `json:"parentCode" validate:"required"`
+}
@@ -0,0 +1,5 @@
+package sectors
+
+type ReorderSectorsReq struct {
+	Codes []string `json:"codes" validate:"required,dive,required"`
+}
@@ -0,0 +1,8 @@
+package sectors
+
+type SectorDeletionResponse struct {
+	Mode        string `json:"mode"`
+	Sectors     int64  `json:"sectors"`
+	Reparented  int64  `json:"reparented"`
+	Assignments int64  `json:"assignments"`
+}
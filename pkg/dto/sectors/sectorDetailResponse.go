@@ -0,0 +1,12 @@
+package sectors
+
+type SectorDetailResponse struct {
+	Sector    SectorResponse     `json:"sector"`
+	Ancestors []SectorBreadcrumb `json:"ancestors"`
+}
+
+type SectorBreadcrumb struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+	Depth int    `json:"depth"`
+}
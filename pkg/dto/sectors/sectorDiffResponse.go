@@ -0,0 +1,17 @@
+package sectors
+
+type SectorDiffEntryResponse struct {
+	Code               string `json:"code,omitempty"`
+	TargetCode         string `json:"targetCode,omitempty"`
+	Label              string `json:"label"`
+	PreviousLabel      string `json:"previousLabel,omitempty"`
+	ParentCode         string `json:"parentCode,omitempty"`
+	PreviousParentCode string `json:"previousParentCode,omitempty"`
+}
+
+type SectorDiffResponse struct {
+	Added   []SectorDiffEntryResponse `json:"added"`
+	Removed []SectorDiffEntryResponse `json:"removed"`
+	Renamed []SectorDiffEntryResponse `json:"renamed"`
+	Moved   []SectorDiffEntryResponse `json:"moved"`
+}
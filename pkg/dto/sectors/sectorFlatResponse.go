@@ -0,0 +1,18 @@
+package sectors
+
+import "micro-fiber-test/pkg/model"
+
+type SectorFlatListResponse struct {
+	Sectors []SectorFlatResponse `json:"sectors"`
+}
+
+type SectorFlatResponse struct {
+	Code       string             `json:"code"`
+	Label      string             `json:"label"`
+	ParentCode string             `json:"parentCode"`
+	Depth      int                `json:"depth"`
+	Position   int                `json:"position"`
+	Status     model.SectorStatus `json:"status"`
+	Version    int64              `json:"version"`
+	Attributes map[string]any     `json:"attributes,omitempty"`
+}
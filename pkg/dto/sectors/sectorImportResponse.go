@@ -0,0 +1,7 @@
+package sectors
+
+type SectorImportResponse struct {
+	Created int64 `json:"created"`
+	Updated int64 `json:"updated"`
+	Moved   int64 `json:"moved"`
+}
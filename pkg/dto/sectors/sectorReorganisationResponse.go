@@ -0,0 +1,8 @@
+package sectors
+
+type SectorReorganisationResponse struct {
+	Code        string `json:"code"`
+	DryRun      bool   `json:"dryRun"`
+	Children    int64  `json:"children"`
+	Assignments int64  `json:"assignments"`
+}
@@ -5,11 +5,14 @@ import (
 )
 
 type SectorResponse struct {
-	Id       int64              `json:"-"`
-	Code     string             `json:"code"`
-	Label    string             `json:"label"`
-	Depth    int                `json:"depth"`
-	ParentId int64              `json:"-"`
-	Status   model.SectorStatus `json:"status"`
-	Children []SectorResponse   `json:"children,omitempty"`
+	Id         int64              `json:"-"`
+	Code       string             `json:"code"`
+	Label      string             `json:"label"`
+	Depth      int                `json:"depth"`
+	ParentId   int64              `json:"-"`
+	Position   int                `json:"position"`
+	Status     model.SectorStatus `json:"status"`
+	Version    int64              `json:"version"`
+	Attributes map[string]any     `json:"attributes,omitempty"`
+	Children   []SectorResponse   `json:"children,omitempty"`
 }
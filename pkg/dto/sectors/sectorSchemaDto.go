@@ -0,0 +1,12 @@
+package sectors
+
+type SectorSchemaDto struct {
+	Attributes []SectorAttributeDefDto `json:"attributes"`
+}
+
+type SectorAttributeDefDto struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Required      bool     `json:"required"`
+	AllowedValues []string `json:"allowedValues,omitempty"`
+}
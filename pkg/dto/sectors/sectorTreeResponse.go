@@ -0,0 +1,11 @@
+package sectors
+
+type SectorTreeListResponse struct {
+	Sectors []SectorTreeResponse `json:"sectors"`
+}
+
+type SectorTreeResponse struct {
+	Code     string               `json:"code"`
+	Label    string               `json:"label"`
+	Children []SectorTreeResponse `json:"children,omitempty"`
+}
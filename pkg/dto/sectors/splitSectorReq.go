@@ -0,0 +1,7 @@
+package sectors
+
+type SplitSectorReq struct {
+	Label      *string  `json:"label" validate:"required,max=50"`
+	ChildCodes []string `json:"childCodes" validate:"dive,required"`
+	UserIds    []string `json:"userIds" validate:"dive,required"`
+}
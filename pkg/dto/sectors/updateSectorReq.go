@@ -0,0 +1,6 @@
+package sectors
+
+type UpdateSectorReq struct {
+	Label      *string        `json:"label" validate:"required,max=50"`
+	Attributes map[string]any `json:"attributes"`
+}
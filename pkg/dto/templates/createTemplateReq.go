@@ -0,0 +1,36 @@
+package templates
+
+import (
+	"fmt"
+	"micro-fiber-test/pkg/validation"
+)
+
+type CreateTemplateReq struct {
+	Label   *string           `json:"label" validate:"required,max=100"`
+	Sectors []TemplateNodeReq `json:"sectors"`
+}
+
+type TemplateNodeReq struct {
+	Label      string            `json:"label"`
+	Attributes map[string]any    `json:"attributes"`
+	Children   []TemplateNodeReq `json:"children"`
+}
+
+// ValidateTemplateNodes checks every node label of a template tree, fields are reported with their path (sectors[0].children[1].label)
+func ValidateTemplateNodes(prefix string, nodes []TemplateNodeReq) []validation.ErrorValidation {
+	var errorsList []validation.ErrorValidation
+	labels := make(map[string]bool, len(nodes))
+	for inc, node := range nodes {
+		field := fmt.Sprintf("%s[%d].label", prefix, inc)
+		if node.Label == "" {
+			errorsList = append(errorsList, validation.ErrorValidation{Field: field, Error: fmt.Sprintf(validation.FieldRequired, field)})
+		} else if len(node.Label) > 50 {
+			errorsList = append(errorsList, validation.ErrorValidation{Field: field, Error: fmt.Sprintf(validation.FieldMaxLength, field, "50")})
+		} else if labels[node.Label] {
+			errorsList = append(errorsList, validation.ErrorValidation{Field: field, Error: fmt.Sprintf(validation.FieldInvalidValue, field, node.Label)})
+		}
+		labels[node.Label] = true
+		errorsList = append(errorsList, ValidateTemplateNodes(fmt.Sprintf("%s[%d].children", prefix, inc), node.Children)...)
+	}
+	return errorsList
+}
@@ -0,0 +1,5 @@
+package templates
+
+type TemplateListResponse struct {
+	Templates []TemplateResponse `json:"templates,omitempty"`
+}
@@ -0,0 +1,13 @@
+package templates
+
+type TemplateResponse struct {
+	Code    string                 `json:"code"`
+	Label   string                 `json:"label"`
+	Sectors []TemplateNodeResponse `json:"sectors"`
+}
+
+type TemplateNodeResponse struct {
+	Label      string                 `json:"label"`
+	Attributes map[string]any         `json:"attributes,omitempty"`
+	Children   []TemplateNodeResponse `json:"children,omitempty"`
+}
@@ -0,0 +1,10 @@
+package tenants
+
+type CreateTenantReq struct {
+	Code  *string `json:"code" validate:"required,max=50"`
+	Label *string `json:"label" validate:"required,max=100"`
+}
+
+type UpdateTenantReq struct {
+	Label *string `json:"label" validate:"required,max=100"`
+}
@@ -0,0 +1,5 @@
+package tenants
+
+type TenantListResponse struct {
+	Tenants []TenantResponse `json:"tenants,omitempty"`
+}
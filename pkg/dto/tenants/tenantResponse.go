@@ -0,0 +1,7 @@
+package tenants
+
+type TenantResponse struct {
+	Code   string `json:"code"`
+	Label  string `json:"label"`
+	Status int    `json:"status"`
+}
@@ -8,4 +8,5 @@ type UserResponse struct {
 	Login      string `json:"login"`
 	Email      string `json:"email"`
 	Status     int    `json:"status"`
+	Version    int64  `json:"version"`
 }
@@ -37,3 +37,9 @@ func ConvertValidationError(errors []validation.ErrorValidation) commons.ApiErro
 		Details: details,
 	}
 }
+
+func ConvertToInternalErrorWithDetails(err error, details []commons.ApiErrorDetails) commons.ApiError {
+	apiErr := ConvertToInternalError(err)
+	apiErr.Details = details
+	return apiErr
+}
@@ -0,0 +1,35 @@
+package endpoints
+
+import (
+	"errors"
+	"micro-fiber-test/pkg/dto/admin"
+	dtos "micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/exceptions"
+	"micro-fiber-test/pkg/service/api"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func MakePurgeEndpoint(defaultRetentionDays int, adminSvc api.AdminServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		retentionDays := ctx.QueryInt("days", defaultRetentionDays)
+		if retentionDays < 0 {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.PurgeInvalidRetention), fiber.StatusBadRequest)
+			return ctx.JSON(apiErr)
+		}
+		report, errPurge := adminSvc.Purge(retentionDays)
+		if errPurge != nil {
+			_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			apiErr := exceptions.ConvertToInternalError(errPurge)
+			return ctx.JSON(apiErr)
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(admin.PurgeResponse{
+			RetentionDays: retentionDays,
+			Users:         report.Users,
+			Sectors:       report.Sectors,
+			Organizations: report.Organizations,
+		})
+	}
+}
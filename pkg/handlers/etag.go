@@ -0,0 +1,113 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	dtos "micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/exceptions"
+	"micro-fiber-test/pkg/model"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// versionETag formats a resource version as a strong entity tag
+func versionETag(version int64) string {
+	return "\"" + strconv.FormatInt(version, 10) + "\""
+}
+
+// sectorsETag formats a strong entity tag covering every listed sector. Positions and depths are hashed along with the
+// versions since sibling shifts and subtree rebases change them without bumping the version.
+func sectorsETag(sectorLists ...[]model.Sector) string {
+	hash := fnv.New64a()
+	for _, sectorsList := range sectorLists {
+		for _, sector := range sectorsList {
+			_, _ = fmt.Fprintf(hash, "%d:%d:%d:%d;", sector.Id, sector.Version, sector.Position, sector.Depth)
+		}
+		_, _ = hash.Write([]byte{'|'})
+	}
+	return "\"s-" + strconv.FormatUint(hash.Sum64(), 16) + "\""
+}
+
+// notModified sets the ETag header of the resource and reports whether it matches If-None-Match
+func notModified(ctx *fiber.Ctx, version int64) bool {
+	return notModifiedTag(ctx, versionETag(version))
+}
+
+// notModifiedTag sets the ETag header of the resource and reports whether it matches If-None-Match, using the weak comparison
+func notModifiedTag(ctx *fiber.Ctx, etag string) bool {
+	ctx.Set(fiber.HeaderETag, etag)
+	ifNoneMatch := ctx.Get(fiber.HeaderIfNoneMatch)
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// contentETag formats a strong entity tag hashing a response body. It serves representations without version of their own,
+// such as paginated lists and diagrams carrying member counts.
+func contentETag(body []byte) string {
+	hash := fnv.New64a()
+	_, _ = hash.Write(body)
+	return "\"c-" + strconv.FormatUint(hash.Sum64(), 16) + "\""
+}
+
+// sendTagged sends the body with its content entity tag, or 304 when it matches If-None-Match
+func sendTagged(ctx *fiber.Ctx, contentType string, body []byte) error {
+	if notModifiedTag(ctx, contentETag(body)) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+	ctx.Set(fiber.HeaderContentType, contentType)
+	_ = ctx.SendStatus(fiber.StatusOK)
+	return ctx.Send(body)
+}
+
+// sendTaggedJSON sends the response as JSON with its content entity tag, or 304 when it matches If-None-Match
+func sendTaggedJSON(ctx *fiber.Ctx, response any) error {
+	body, errJson := json.Marshal(response)
+	if errJson != nil {
+		return errJson
+	}
+	return sendTagged(ctx, fiber.MIMEApplicationJSON, body)
+}
+
+// ifMatchVersion evaluates the If-Match header against the resource in its current version, found being false when it
+// does not exist. representationTags are the other entity tags the resource is currently served with, such as the detail
+// tag of a sector. It returns the version the write must still expect: 0 when the header is absent or "*", the current
+// version when one of the listed entity tags matches it. ok is false when the precondition fails: no tag matches a missing
+// resource, and If-Match uses the strong comparison so weak tags never match.
+func ifMatchVersion(ctx *fiber.Ctx, current int64, found bool, representationTags ...string) (version int64, ok bool) {
+	ifMatch := strings.TrimSpace(ctx.Get(fiber.HeaderIfMatch))
+	if ifMatch == "" {
+		return 0, true
+	}
+	if !found {
+		return 0, false
+	}
+	if ifMatch == "*" {
+		return 0, true
+	}
+	currentTag := versionETag(current)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == currentTag || slices.Contains(representationTags, tag) {
+			return current, true
+		}
+	}
+	return 0, false
+}
+
+func sendPreconditionFailed(ctx *fiber.Ctx) error {
+	_ = ctx.SendStatus(fiber.StatusPreconditionFailed)
+	return ctx.JSON(exceptions.ConvertToFunctionalError(errors.New(dtos.VersionMismatch), fiber.StatusPreconditionFailed))
+}
@@ -0,0 +1,82 @@
+package endpoints
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// ifMatchStatus runs ifMatchVersion for a resource in version 3, or a missing one, and returns the response status
+// with the expected version
+func ifMatchStatus(t *testing.T, ifMatch string, found bool) (int, string) {
+	app := fiber.New()
+	app.Put("/", func(ctx *fiber.Ctx) error {
+		version, ok := ifMatchVersion(ctx, 3, found)
+		if !ok {
+			return sendPreconditionFailed(ctx)
+		}
+		return ctx.SendString(strconv.FormatInt(version, 10))
+	})
+	req := httptest.NewRequest(fiber.MethodPut, "/", nil)
+	if ifMatch != "" {
+		req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+	}
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	body := make([]byte, 16)
+	n, _ := resp.Body.Read(body)
+	return resp.StatusCode, string(body[:n])
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	status, version := ifMatchStatus(t, "", true)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "0", version)
+
+	status, version = ifMatchStatus(t, `"3"`, true)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "3", version)
+
+	status, version = ifMatchStatus(t, `"2", "3"`, true)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "3", version)
+
+	status, version = ifMatchStatus(t, "*", true)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "0", version)
+
+	status, _ = ifMatchStatus(t, `"2", "4"`, true)
+	assert.Equal(t, fiber.StatusPreconditionFailed, status)
+	status, _ = ifMatchStatus(t, `W/"3"`, true)
+	assert.Equal(t, fiber.StatusPreconditionFailed, status)
+
+	// No entity tag matches a missing resource, not even "*"
+	status, _ = ifMatchStatus(t, "*", false)
+	assert.Equal(t, fiber.StatusPreconditionFailed, status)
+	status, version = ifMatchStatus(t, "", false)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "0", version)
+}
+
+func TestSendTaggedJSON(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(ctx *fiber.Ctx) error {
+		return sendTaggedJSON(ctx, fiber.Map{"items": []string{"a", "b"}})
+	})
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+	etag := resp.Header.Get(fiber.HeaderETag)
+	assert.NotEmpty(t, etag)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, "W/"+etag)
+	resp, err = app.Test(req)
+	assert.Nil(t, err)
+	assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, etag, resp.Header.Get(fiber.HeaderETag))
+}
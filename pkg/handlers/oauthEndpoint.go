@@ -14,7 +14,10 @@ import (
 	"io"
 	"micro-fiber-test/pkg/dto/commons"
 	"micro-fiber-test/pkg/exceptions"
+	"micro-fiber-test/pkg/helpers"
+	"micro-fiber-test/pkg/middlewares"
 	"micro-fiber-test/pkg/model"
+	"micro-fiber-test/pkg/service/api"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -23,8 +26,9 @@ import (
 )
 
 const (
-	oAuthState = "oauthstate"
-	cVerifier  = "cverifier"
+	oAuthState  = "oauthstate"
+	cVerifier   = "cverifier"
+	oAuthTenant = "oauthtenant"
 )
 
 var httpRetryFunction = func(client *resty.Client, resp *resty.Response) (time.Duration, error) {
@@ -42,7 +46,7 @@ type GithubUserInfos struct {
 	Name  string `json:"name,omitempty"`
 }
 
-func MakeOAuthAuthorize(store *session.Store, oauthCallback string, oAuthClientId string, oauthClientSecret string, oauthDebug bool, githubUserInfos string) func(ctx *fiber.Ctx) error {
+func MakeOAuthAuthorize(store *session.Store, tenantSvc api.TenantServiceInterface, oauthCallback string, oAuthClientId string, oauthClientSecret string, oauthDebug bool, githubUserInfos string) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
 
 		code := ctx.Query("code")
@@ -78,10 +82,13 @@ func MakeOAuthAuthorize(store *session.Store, oauthCallback string, oAuthClientI
 
 		codeVerifier := httpSession.Get(cVerifier)
 		reqURL := fmt.Sprintf(oauthCallback, oAuthClientId, oauthClientSecret, code, codeVerifier)
+		requestedTenant, _ := httpSession.Get(oAuthTenant).(string)
 
 		// Delete from session
 		httpSession.Delete(oAuthState)
 		httpSession.Delete(cVerifier)
+		httpSession.Delete(oAuthTenant)
+		httpSession.Delete(middlewares.TenantSessionKey)
 
 		client := resty.New()
 		client.SetDebug(oauthDebug)
@@ -102,18 +109,38 @@ func MakeOAuthAuthorize(store *session.Store, oauthCallback string, oAuthClientI
 			apiError := exceptions.ConvertToInternalError(errDecode)
 			return ctx.JSON(apiError)
 		}
+		userInfos, err := getUserInfos(githubUserInfos, t.AccessToken)
+		if err != nil {
+			return err
+		}
+
+		// The session claims the tenant the authenticated user belongs to, the tenant resolver trusts it over headers
+		userTenants, errTenants := tenantSvc.FindCodesByUserLogin(userInfos.Login)
+		if errTenants != nil {
+			_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			apiError := exceptions.ConvertToInternalError(errTenants)
+			return ctx.JSON(apiError)
+		}
+		tenantCode, errSelect := helpers.SelectLoginTenant(requestedTenant, userTenants)
+		if errSelect != nil {
+			_ = httpSession.Save()
+			status := fiber.StatusForbidden
+			if errSelect.Error() == commons.TenantNotResolved {
+				status = fiber.StatusBadRequest
+			}
+			apiError := exceptions.ConvertToFunctionalError(errSelect, status)
+			_ = ctx.SendStatus(status)
+			return ctx.JSON(apiError)
+		}
+
 		httpSession.Set("tkn", t.AccessToken)
+		httpSession.Set(middlewares.TenantSessionKey, tenantCode)
 		errSessionSave := httpSession.Save()
 		if errSessionSave != nil {
 			fmt.Printf("error session save [%s]", errSessionSave.Error())
 			return errSessionSave
 		}
 
-		userInfos, err := getUserInfos(githubUserInfos, t.AccessToken)
-		if err != nil {
-			return err
-		}
-
 		return ctx.Render("welcome", fiber.Map{
 			"userName": userInfos.Name,
 		})
@@ -137,6 +164,8 @@ func MakeGitlabAuthentication(store *session.Store, oauthGithub string, clientId
 			return ctx.JSON(apiError)
 		}
 		httpSession.Set(oAuthState, state)
+		// Tenant the user asks to log into, checked against its memberships once authenticated
+		httpSession.Set(oAuthTenant, ctx.Query("tenant", ctx.Get(middlewares.TenantHeader)))
 
 		// Generate code verifier
 		buf, errRnd := randomBytes(32)
@@ -1,15 +1,19 @@
 package endpoints
 
 import (
+	"errors"
 	"fmt"
 	"micro-fiber-test/pkg/converters"
 	dtos "micro-fiber-test/pkg/dto/commons"
 	"micro-fiber-test/pkg/dto/orgs"
 	"micro-fiber-test/pkg/exceptions"
+	"micro-fiber-test/pkg/helpers"
 	"micro-fiber-test/pkg/middlewares"
 	"micro-fiber-test/pkg/model"
 	"micro-fiber-test/pkg/service/api"
 	"micro-fiber-test/pkg/validation"
+	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2"
@@ -19,16 +23,17 @@ import (
 
 var validate = validator.New()
 
-func MakeOrgCreateEndpoint(rdbmsUrl string, defaultTenantId int64, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeOrgCreateEndpoint(rdbmsUrl string, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
 		var bsTxId interface{} = ctx.Locals(middlewares.BsTxId)
 		fmt.Printf("Bs Transaction id: [%s]", bsTxId)
 
 		orgReq := orgs.CreateOrgRequest{}
 		var json = jsoniter.ConfigCompatibleWithStandardLibrary
 		if err := json.Unmarshal(ctx.Body(), &orgReq); err != nil {
-			_ = ctx.SendStatus(fiber.StatusInternalServerError)
-			apiErr := exceptions.ConvertToInternalError(err)
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.OrgInvalidPayload), fiber.StatusBadRequest)
 			return ctx.JSON(apiErr)
 		}
 
@@ -39,23 +44,36 @@ func MakeOrgCreateEndpoint(rdbmsUrl string, defaultTenantId int64, orgSvc api.Or
 			return ctx.JSON(apiError)
 		}
 
-		org := converters.ConvertOrgReqToDaoModel(defaultTenantId, orgReq)
+		org := converters.ConvertOrgReqToDaoModel(tenantId, orgReq)
+		if !helpers.IsOrgTypeValid(org.Type) {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiError := exceptions.ConvertValidationError(invalidOrgTypeErrors(org.Type))
+			return ctx.JSON(apiError)
+		}
 		codeUUID := uuid.New().String()
 		org.Code = codeUUID
-		switch org.Status {
-		case model.OrgStatusDraft, model.OrgStatusActive, model.OrgStatusInactive, model.OrgStatusDeleted:
-		default:
+		if !helpers.IsOrgInitialStatus(org.Status) {
 			_ = ctx.SendStatus(fiber.StatusBadRequest)
 			apiErr := dtos.ApiError{
 				Code:    fiber.StatusBadRequest,
 				Kind:    string(dtos.ErrorTypeFunctional),
-				Message: fmt.Sprintf("Invalid org status [%d]", orgReq.Status),
+				Message: fmt.Sprintf("Invalid org status [%d]", org.Status),
 			}
 			return ctx.JSON(apiErr)
 		}
-		_, err := orgSvc.Create(rdbmsUrl, defaultTenantId, org)
+		templateCode := ""
+		if orgReq.Template != nil {
+			templateCode = *orgReq.Template
+		}
+		_, err := orgSvc.Create(rdbmsUrl, tenantId, org, templateCode)
 		if err != nil {
-			if err.Error() == dtos.OrgAlreadyExistsByCode {
+			if err.Error() == dtos.TemplateNotFound {
+				_ = ctx.SendStatus(fiber.StatusNotFound)
+				apiErr := exceptions.ConvertToFunctionalError(err, fiber.StatusNotFound)
+				return ctx.JSON(apiErr)
+			} else if err.Error() == dtos.OrgParentNotFound || err.Error() == dtos.OrgParentTypeInvalid {
+				return sendOrgError(ctx, err)
+			} else if err.Error() == dtos.OrgAlreadyExistsByCode || err.Error() == dtos.OrgAlreadyExistsByLabel {
 				_ = ctx.SendStatus(fiber.StatusConflict)
 				apiErr := exceptions.ConvertToFunctionalError(err, fiber.StatusConflict)
 				return ctx.JSON(apiErr)
@@ -73,23 +91,33 @@ func MakeOrgCreateEndpoint(rdbmsUrl string, defaultTenantId int64, orgSvc api.Or
 	}
 }
 
-func MakeOrgUpdateEndpoint(defaultTenantId int64, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeOrgUpdateEndpoint(orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
 		orgCode := ctx.Params("orgCode")
+		version, sent, errSend := orgIfMatch(ctx, orgSvc, tenantId, orgCode)
+		if sent {
+			return errSend
+		}
 		payload := struct {
-			Label string `json:"label"`
+			Label string `json:"label" validate:"required,max=50"`
 		}{}
 		if err := ctx.BodyParser(&payload); err != nil {
-			_ = ctx.SendStatus(fiber.StatusInternalServerError)
-			apiErr := exceptions.ConvertToInternalError(err)
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.OrgInvalidPayload), fiber.StatusBadRequest)
 			return ctx.JSON(apiErr)
 		}
-		errUpdate := orgSvc.Update(defaultTenantId, orgCode, payload.Label)
 
+		errValid := validate.Struct(payload)
+		if errValid != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiError := exceptions.ConvertValidationError(validation.ConvertValidationErrors(errValid))
+			return ctx.JSON(apiError)
+		}
+
+		errUpdate := orgSvc.Update(tenantId, orgCode, payload.Label, version)
 		if errUpdate != nil {
-			_ = ctx.SendStatus(fiber.StatusInternalServerError)
-			apiErr := exceptions.ConvertToInternalError(errUpdate)
-			return ctx.JSON(apiErr)
+			return sendOrgError(ctx, errUpdate)
 		} else {
 			_ = ctx.SendStatus(fiber.StatusNoContent)
 			return nil
@@ -98,26 +126,34 @@ func MakeOrgUpdateEndpoint(defaultTenantId int64, orgSvc api.OrganizationService
 	}
 }
 
-func MakeOrgDeleteEndpoint(defaultTenantId int64, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeOrgDeleteEndpoint(orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
 		orgCode := ctx.Params("orgCode")
-		_, errFind := orgSvc.FindByCode(defaultTenantId, orgCode)
-		if errFind != nil {
-			if errFind.Error() == dtos.OrgDoesNotExistByCode {
-				_ = ctx.SendStatus(fiber.StatusNotFound)
-				apiErr := exceptions.ConvertToFunctionalError(errFind, fiber.StatusNotFound)
-				return ctx.JSON(apiErr)
-			} else {
-				_ = ctx.SendStatus(fiber.StatusInternalServerError)
-				apiErr := exceptions.ConvertToInternalError(errFind)
-				return ctx.JSON(apiErr)
-			}
+		version, sent, errSend := orgIfMatch(ctx, orgSvc, tenantId, orgCode)
+		if sent {
+			return errSend
 		} else {
-			errUpdate := orgSvc.Delete(defaultTenantId, orgCode)
-			if errUpdate != nil {
-				_ = ctx.SendStatus(fiber.StatusInternalServerError)
-				apiErr := exceptions.ConvertToInternalError(errUpdate)
-				return ctx.JSON(apiErr)
+			options := model.OrgDeleteOptions{
+				Strategy:      model.OrgDeleteStrategy(ctx.Query("strategy", string(model.OrgDeleteRefuse))),
+				TargetOrgCode: ctx.Query("targetOrg", ""),
+				DryRun:        ctx.QueryBool("dryRun", false),
+				Version:       version,
+			}
+			report, errDelete := orgSvc.Delete(tenantId, orgCode, options)
+			if errDelete != nil {
+				return sendOrgError(ctx, errDelete)
+			} else if options.DryRun {
+				_ = ctx.SendStatus(fiber.StatusOK)
+				return ctx.JSON(orgs.OrgDeletionResponse{
+					Strategy:  string(options.Strategy),
+					TargetOrg: options.TargetOrgCode,
+					DryRun:    true,
+					Users:     report.Users,
+					Sectors:   report.Sectors,
+					Children:  report.Children,
+					Refused:   report.Refused,
+				})
 			} else {
 				_ = ctx.SendStatus(fiber.StatusNoContent)
 				return nil
@@ -126,10 +162,11 @@ func MakeOrgDeleteEndpoint(defaultTenantId int64, orgSvc api.OrganizationService
 	}
 }
 
-func MakeOrgFindByCodeEndpoint(defaultTenantId int64, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeOrgFindByCodeEndpoint(orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
 		orgCode := ctx.Params("orgCode")
-		org, errFind := orgSvc.FindByCode(defaultTenantId, orgCode)
+		org, errFind := orgSvc.FindByCode(tenantId, orgCode)
 		if errFind != nil {
 			if errFind.Error() == dtos.OrgDoesNotExistByCode {
 				_ = ctx.SendStatus(fiber.StatusNotFound)
@@ -141,6 +178,9 @@ func MakeOrgFindByCodeEndpoint(defaultTenantId int64, orgSvc api.OrganizationSer
 				return ctx.JSON(apiErr)
 			}
 		} else {
+			if notModified(ctx, org.Version) {
+				return ctx.SendStatus(fiber.StatusNotModified)
+			}
 			orgResponse := converters.ConvertOrgModelToOrgResp(org)
 			_ = ctx.SendStatus(fiber.StatusOK)
 			return ctx.JSON(orgResponse)
@@ -148,24 +188,236 @@ func MakeOrgFindByCodeEndpoint(defaultTenantId int64, orgSvc api.OrganizationSer
 	}
 }
 
-func MakeOrgFindAll(defaultTenantId int64, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeOrgFindAll(orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
-		orgsList, errFindAll := orgSvc.FindAll(defaultTenantId)
+		tenantId := middlewares.GetTenantId(ctx)
+		orgFilterCriteria, errorsList := buildOrgCriteria(tenantId, ctx)
+		if len(errorsList) > 0 {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiError := exceptions.ConvertValidationError(errorsList)
+			return ctx.JSON(apiError)
+		}
+		parentCode := ctx.Query("parent", "")
+		if parentCode != "" {
+			parent, errParent := orgSvc.FindByCode(tenantId, parentCode)
+			if errParent != nil {
+				if errParent.Error() == dtos.OrgDoesNotExistByCode {
+					return sendOrgError(ctx, errors.New(dtos.OrgParentNotFound))
+				}
+				return sendOrgError(ctx, errParent)
+			}
+			orgFilterCriteria.ParentId = &parent.Id
+		}
+
+		orgsSearch, errFindAll := orgSvc.FindByCriteria(orgFilterCriteria)
 		if errFindAll != nil {
 			_ = ctx.SendStatus(fiber.StatusInternalServerError)
 			apiErr := exceptions.ConvertToInternalError(errFindAll)
 			return ctx.JSON(apiErr)
 		} else {
-			orgResponseList := make([]orgs.OrganizationResponse, len(orgsList))
-			for inc, org := range orgsList {
+			orgResponseList := make([]orgs.OrganizationResponse, len(orgsSearch.Organizations))
+			for inc, org := range orgsSearch.Organizations {
 				orgResponse := converters.ConvertOrgModelToOrgResp(org)
 				orgResponseList[inc] = orgResponse
 			}
 			orgListResponse := orgs.OrganizationListResponse{
 				Organizations: orgResponseList,
+				Pagination:    buildPagination(orgFilterCriteria.Page, orgFilterCriteria.RowsPerPage, orgsSearch.NbResults),
 			}
-			_ = ctx.SendStatus(fiber.StatusOK)
-			return ctx.JSON(orgListResponse)
+			return sendTaggedJSON(ctx, orgListResponse)
+		}
+	}
+}
+
+func MakeOrgChildrenEndpoint(orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+		children, errChildren := orgSvc.FindChildren(tenantId, ctx.Params("orgCode"))
+		if errChildren != nil {
+			return sendOrgError(ctx, errChildren)
+		}
+		orgResponseList := make([]orgs.OrganizationResponse, len(children))
+		for inc, org := range children {
+			orgResponseList[inc] = converters.ConvertOrgModelToOrgResp(org)
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(orgs.OrganizationListResponse{Organizations: orgResponseList})
+	}
+}
+
+func MakeOrgTreeEndpoint(orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+		orgsList, errFindAll := orgSvc.FindAll(tenantId)
+		if errFindAll != nil {
+			_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			apiErr := exceptions.ConvertToInternalError(errFindAll)
+			return ctx.JSON(apiErr)
+		}
+		orgTreeList := make([]orgs.OrgTreeResponse, len(orgsList))
+		for inc, org := range orgsList {
+			orgTreeList[inc] = converters.ConvertOrgModelToOrgTreeResp(org)
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(orgs.OrgTreeListResponse{Organizations: helpers.BuildOrgTree(orgTreeList)})
+	}
+}
+
+func MakeOrgLifecycleEndpoint(action model.OrgLifecycleAction, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+		orgCode := ctx.Params("orgCode")
+		errStatus := orgSvc.ChangeStatus(tenantId, orgCode, action)
+		if errStatus != nil {
+			return sendOrgError(ctx, errStatus)
+		}
+		_ = ctx.SendStatus(fiber.StatusNoContent)
+		return nil
+	}
+}
+
+func MakeOrgPatchEndpoint(orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+		orgCode := ctx.Params("orgCode")
+		version, sent, errSend := orgIfMatch(ctx, orgSvc, tenantId, orgCode)
+		if sent {
+			return errSend
+		}
+
+		patch, errorsList, errParse := orgs.ParseOrgMergePatch(ctx.Body())
+		if errParse != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.OrgInvalidPayload), fiber.StatusBadRequest)
+			return ctx.JSON(apiErr)
+		}
+		if patch.Type != nil && !helpers.IsOrgTypeValid(*patch.Type) {
+			errorsList = append(errorsList, invalidOrgTypeErrors(*patch.Type)...)
+		}
+		if len(errorsList) > 0 {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiError := exceptions.ConvertValidationError(errorsList)
+			return ctx.JSON(apiError)
+		}
+
+		errPatch := orgSvc.Patch(tenantId, orgCode, patch, version)
+		if errPatch != nil {
+			return sendOrgError(ctx, errPatch)
 		}
+		_ = ctx.SendStatus(fiber.StatusNoContent)
+		return nil
+	}
+}
+
+func MakeOrgCloneEndpoint(orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+		orgCode := ctx.Params("orgCode")
+		cloneReq := orgs.CloneOrgRequest{}
+		if err := ctx.BodyParser(&cloneReq); err != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.OrgInvalidPayload), fiber.StatusBadRequest)
+			return ctx.JSON(apiErr)
+		}
+
+		errValid := validate.Struct(cloneReq)
+		if errValid != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiError := exceptions.ConvertValidationError(validation.ConvertValidationErrors(errValid))
+			return ctx.JSON(apiError)
+		}
+
+		cloneCode, errClone := orgSvc.Clone(tenantId, orgCode, model.OrgCloneOptions{Label: *cloneReq.Label, CopyMembers: cloneReq.CopyMembers})
+		if errClone != nil {
+			return sendOrgError(ctx, errClone)
+		}
+		_ = ctx.SendStatus(fiber.StatusCreated)
+		return ctx.JSON(dtos.CodeResponse{Code: cloneCode})
+	}
+}
+
+// orgIfMatch evaluates If-Match against the organization and returns the version its write must expect.
+// When sent is true the response is already sent: 412 when the precondition fails, 404 when the organization does not exist.
+func orgIfMatch(ctx *fiber.Ctx, orgSvc api.OrganizationServiceInterface, tenantId int64, orgCode string) (version int64, sent bool, err error) {
+	org, errFind := orgSvc.FindByCode(tenantId, orgCode)
+	if errFind != nil && errFind.Error() != dtos.OrgDoesNotExistByCode {
+		return 0, true, sendOrgError(ctx, errFind)
+	}
+	version, versionOk := ifMatchVersion(ctx, org.Version, errFind == nil)
+	if !versionOk {
+		return 0, true, sendPreconditionFailed(ctx)
+	}
+	if errFind != nil {
+		return 0, true, sendOrgError(ctx, errFind)
+	}
+	return version, false, nil
+}
+
+func invalidOrgTypeErrors(kind model.OrganizationType) []validation.ErrorValidation {
+	return []validation.ErrorValidation{{Field: "type", Error: fmt.Sprintf(validation.FieldInvalidValue, "type", kind)}}
+}
+
+func sendOrgError(ctx *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case dtos.VersionMismatch:
+		return sendPreconditionFailed(ctx)
+	case dtos.OrgDoesNotExistByCode:
+		_ = ctx.SendStatus(fiber.StatusNotFound)
+		return ctx.JSON(exceptions.ConvertToFunctionalError(err, fiber.StatusNotFound))
+	case dtos.OrgMoveTargetNotFound, dtos.OrgParentNotFound:
+		_ = ctx.SendStatus(fiber.StatusNotFound)
+		return ctx.JSON(exceptions.ConvertToFunctionalError(err, fiber.StatusNotFound))
+	case dtos.OrgInvalidType, dtos.OrgInvalidStrategy, dtos.OrgMoveTargetInvalid, dtos.OrgParentTypeInvalid, dtos.OrgCloneMembersInvalid:
+		_ = ctx.SendStatus(fiber.StatusBadRequest)
+		return ctx.JSON(exceptions.ConvertToFunctionalError(err, fiber.StatusBadRequest))
+	case dtos.OrgAlreadyExistsByCode, dtos.OrgAlreadyExistsByLabel, dtos.OrgIllegalTransition, dtos.OrgHasUsers, dtos.OrgNotActive, dtos.OrgParentCycle, dtos.OrgHasChildren:
+		_ = ctx.SendStatus(fiber.StatusConflict)
+		return ctx.JSON(exceptions.ConvertToFunctionalError(err, fiber.StatusConflict))
+	default:
+		_ = ctx.SendStatus(fiber.StatusInternalServerError)
+		return ctx.JSON(exceptions.ConvertToInternalError(err))
+	}
+}
+
+func buildOrgCriteria(tenantId int64, ctx *fiber.Ctx) (model.OrgFilterCriteria, []validation.ErrorValidation) {
+	var errorsList []validation.ErrorValidation
+	orgFilterCriteria := model.OrgFilterCriteria{TenantId: tenantId}
+
+	orgFilterCriteria.Label = ctx.Query("label", "")
+	kind := model.OrganizationType(ctx.Query("type", ""))
+	if kind != "" && !helpers.IsOrgTypeValid(kind) {
+		errorsList = append(errorsList, invalidOrgTypeErrors(kind)...)
+	}
+	orgFilterCriteria.Type = kind
+
+	statusStr := ctx.Query("status", "")
+	if statusStr != "" {
+		status, errStatus := strconv.Atoi(statusStr)
+		if errStatus != nil {
+			errorsList = append(errorsList, validation.ErrorValidation{Field: "status", Error: fmt.Sprintf(validation.FieldInvalidValue, "status", statusStr)})
+		} else {
+			orgStatus := model.OrganizationStatus(status)
+			orgFilterCriteria.Status = &orgStatus
+		}
+	}
+
+	sortStr := ctx.Query("sort", string(model.OrgSortByLabel))
+	orgFilterCriteria.SortDesc = strings.HasPrefix(sortStr, "-")
+	orgFilterCriteria.SortBy = model.OrgSortField(strings.TrimPrefix(sortStr, "-"))
+	if orgFilterCriteria.SortBy != model.OrgSortByLabel && orgFilterCriteria.SortBy != model.OrgSortByCode {
+		errorsList = append(errorsList, validation.ErrorValidation{Field: "sort", Error: fmt.Sprintf(validation.FieldInvalidValue, "sort", sortStr)})
+	}
+
+	rowsPerPage, errRows := strconv.Atoi(ctx.Query("rows", "20"))
+	if errRows != nil || rowsPerPage < 1 || rowsPerPage > maxRowsPerPage {
+		errorsList = append(errorsList, validation.ErrorValidation{Field: "rows", Error: fmt.Sprintf(validation.FieldInvalidValue, "rows", ctx.Query("rows"))})
+	}
+	orgFilterCriteria.RowsPerPage = rowsPerPage
+
+	curPage, errPage := strconv.Atoi(ctx.Query("page", "1"))
+	if errPage != nil || curPage < 1 {
+		errorsList = append(errorsList, validation.ErrorValidation{Field: "page", Error: fmt.Sprintf(validation.FieldInvalidValue, "page", ctx.Query("page"))})
 	}
+	orgFilterCriteria.Page = curPage
+	return orgFilterCriteria, errorsList
 }
@@ -0,0 +1,18 @@
+package endpoints
+
+import dtos "micro-fiber-test/pkg/dto/commons"
+
+const maxRowsPerPage = 500
+
+// buildPagination computes the pagination block of a search response
+func buildPagination(page int, rowsPerPage int, totalCount int) dtos.Pagination {
+	nbPages := 1
+	if rowsPerPage > 0 && totalCount > rowsPerPage {
+		nbPages = (totalCount + rowsPerPage - 1) / rowsPerPage
+	}
+	return dtos.Pagination{
+		Page:       page,
+		NbPages:    nbPages,
+		TotalCount: totalCount,
+	}
+}
@@ -0,0 +1,60 @@
+package endpoints
+
+import (
+	"micro-fiber-test/pkg/converters"
+	"micro-fiber-test/pkg/dto/sectors"
+	"micro-fiber-test/pkg/exceptions"
+	"micro-fiber-test/pkg/helpers"
+	"micro-fiber-test/pkg/middlewares"
+	"micro-fiber-test/pkg/service/api"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func MakeSectorSchemaFind(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+		defs, errSchema := sectSvc.FindAttributeSchema(tenantId, org.Id)
+		if errSchema != nil {
+			return sendSectorError(ctx, errSchema)
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(converters.ConvertSectorSchemaModelToDto(defs))
+	}
+}
+
+func MakeSectorSchemaSave(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+
+		schemaReq := sectors.SectorSchemaDto{}
+		if err := ctx.BodyParser(&schemaReq); err != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(err, fiber.StatusBadRequest)
+			return ctx.JSON(apiErr)
+		}
+		defs := converters.ConvertSectorSchemaDtoToModel(schemaReq)
+		errorsList := helpers.ValidateSectorAttributeSchema(defs)
+		if len(errorsList) > 0 {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			return ctx.JSON(exceptions.ConvertValidationError(errorsList))
+		}
+
+		errSave := sectSvc.SaveAttributeSchema(tenantId, org.Id, defs)
+		if errSave != nil {
+			return sendSectorError(ctx, errSave)
+		}
+		_ = ctx.SendStatus(fiber.StatusNoContent)
+		return nil
+	}
+}
@@ -1,27 +1,34 @@
 package endpoints
 
 import (
+	"bytes"
 	"database/sql"
 	"errors"
+	"fmt"
 	"micro-fiber-test/pkg/converters"
 	dtos "micro-fiber-test/pkg/dto/commons"
 	"micro-fiber-test/pkg/dto/orgs"
 	"micro-fiber-test/pkg/dto/sectors"
 	"micro-fiber-test/pkg/exceptions"
 	"micro-fiber-test/pkg/helpers"
+	"micro-fiber-test/pkg/middlewares"
 	"micro-fiber-test/pkg/model"
 	"micro-fiber-test/pkg/service/api"
 	"micro-fiber-test/pkg/validation"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
-func MakeSectorsFindByOrga(defaultTenantId int64, orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeSectorsFindByOrga(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
 		var nilOrg model.Organization
 		orgCode := ctx.Params("orgCode")
-		org, errFindOrga := orgSvc.FindByCode(defaultTenantId, orgCode)
+		org, errFindOrga := orgSvc.FindByCode(tenantId, orgCode)
 		if errFindOrga != nil {
 			if errFindOrga.Error() == dtos.OrgDoesNotExistByCode {
 				_ = ctx.SendStatus(fiber.StatusNotFound)
@@ -39,12 +46,23 @@ func MakeSectorsFindByOrga(defaultTenantId int64, orgSvc api.OrganizationService
 			return ctx.JSON(apiErr)
 		}
 
-		sectorsList, errFindAll := sectSvc.FindSectorsByTenantOrg(defaultTenantId, org.Id)
+		statuses, errorsList := parseSectorStatuses(ctx)
+		format, errorsFormat := parseSectorDiagramFormat(ctx)
+		errorsList = append(errorsList, errorsFormat...)
+		maxDepth, errorsDepth := parseMaxDepth(ctx)
+		errorsList = append(errorsList, errorsDepth...)
+		if len(errorsList) > 0 {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			return ctx.JSON(exceptions.ConvertValidationError(errorsList))
+		}
+
+		sectorsList, errFindAll := sectSvc.FindSectorsByTenantOrg(tenantId, org.Id, statuses)
 		if errFindAll != nil {
 			_ = ctx.SendStatus(fiber.StatusInternalServerError)
 			apiErr := exceptions.ConvertToInternalError(errFindAll)
 			return ctx.JSON(apiErr)
 		} else {
+			sectorsList = helpers.FilterSectorsByAttributes(sectorsList, parseAttributeFilters(ctx))
 			sectorsResponseList := make([]sectors.SectorResponse, len(sectorsList))
 			for inc, s := range sectorsList {
 				sgResponse := converters.ConvertSectorModelToSectorResp(s)
@@ -52,7 +70,25 @@ func MakeSectorsFindByOrga(defaultTenantId int64, orgSvc api.OrganizationService
 			}
 			s, errHierarchy := helpers.BuildSectorsHierarchy(sectorsResponseList)
 			if errHierarchy != nil {
-				return nil
+				return sendSectorError(ctx, errHierarchy)
+			}
+			if format != "" {
+				options := model.SectorDiagramOptions{MaxDepth: maxDepth}
+				if ctx.QueryBool("withUsers", false) {
+					counts, errCount := sectSvc.CountMembersBySector(tenantId, org.Id)
+					if errCount != nil {
+						return sendSectorError(ctx, errCount)
+					}
+					options.MemberCounts = counts
+				}
+				// Member counts change without sector version, diagrams are tagged from their content
+				if format == model.SectorDiagramDot {
+					return sendTagged(ctx, "text/vnd.graphviz; charset=utf-8", []byte(helpers.RenderSectorsDot(s, options)))
+				}
+				return sendTagged(ctx, "text/vnd.mermaid; charset=utf-8", []byte(helpers.RenderSectorsMermaid(s, options)))
+			}
+			if notModifiedTag(ctx, sectorsETag(sectorsList)) {
+				return ctx.SendStatus(fiber.StatusNotModified)
 			}
 			sectListResponse := sectors.SectorListResponse{
 				Sectors: s,
@@ -63,16 +99,16 @@ func MakeSectorsFindByOrga(defaultTenantId int64, orgSvc api.OrganizationService
 	}
 }
 
-func MakeSectorCreateEndpoint(defaultTenantId int64, orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeSectorCreateEndpoint(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
 
+		tenantId := middlewares.GetTenantId(ctx)
 		var nilOrg model.Organization
-		var nilSector model.Sector
 
 		orgCode := ctx.Params("orgCode")
 
 		// Ensure organization exists
-		org, errFindOrga := orgSvc.FindByCode(defaultTenantId, orgCode)
+		org, errFindOrga := orgSvc.FindByCode(tenantId, orgCode)
 		if errFindOrga != nil {
 			_ = ctx.SendStatus(fiber.StatusInternalServerError)
 			apiErr := exceptions.ConvertToInternalError(errFindOrga)
@@ -83,6 +119,11 @@ func MakeSectorCreateEndpoint(defaultTenantId int64, orgSvc api.OrganizationServ
 			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.OrgNotFound), fiber.StatusNotFound)
 			return ctx.JSON(apiErr)
 		}
+		if !helpers.OrgAcceptsChildren(org.Status) {
+			_ = ctx.SendStatus(fiber.StatusConflict)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.OrgNotActive), fiber.StatusConflict)
+			return ctx.JSON(apiErr)
+		}
 
 		// Deserialize request
 		sectorReq := orgs.CreateSectorReq{}
@@ -100,18 +141,39 @@ func MakeSectorCreateEndpoint(defaultTenantId int64, orgSvc api.OrganizationServ
 			return ctx.JSON(apiError)
 		}
 
-		secModel := orgs.ConvertSectorReqToDaoModel(defaultTenantId, sectorReq)
+		errorsList, errAttributes := sectSvc.ValidateAttributes(tenantId, org.Id, sectorReq.Attributes)
+		if errAttributes != nil {
+			return sendSectorError(ctx, errAttributes)
+		}
+		if len(errorsList) > 0 {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			return ctx.JSON(exceptions.ConvertValidationError(errorsList))
+		}
+
+		secModel := orgs.ConvertSectorReqToDaoModel(tenantId, sectorReq)
 		secModel.OrgId = org.Id
 		secModel.HasParent = true
 		codeUUID := uuid.New().String()
 		secModel.Code = codeUUID
-		if sectorReq.ParentCode != "" {
-			// Find parent sector
-			parentSector, errParent := sectSvc.FindByCode(defaultTenantId, sectorReq.ParentCode)
-			if errParent != nil {
-				return errParent
+		if sectorReq.ParentCode != "" && sectorReq.ParentPath != "" {
+			return sendSectorError(ctx, errors.New(dtos.SectorParentAmbiguous))
+		}
+		if sectorReq.ParentCode != "" || sectorReq.ParentPath != "" {
+			// Find parent sector, by code or by label path
+			var parentSector model.Sector
+			var errParent error
+			if sectorReq.ParentPath != "" {
+				parentSector, errParent = sectSvc.FindByLabelPath(tenantId, org.Id, sectorReq.ParentPath)
+				if errParent != nil {
+					return sendSectorError(ctx, errParent)
+				}
+			} else {
+				parentSector, errParent = sectSvc.FindByCode(tenantId, sectorReq.ParentCode)
+				if errParent != nil {
+					return errParent
+				}
 			}
-			if parentSector != nilSector {
+			if parentSector.Id > 0 {
 				nillableInt64 := sql.NullInt64{
 					Int64: parentSector.Id,
 					Valid: true,
@@ -121,7 +183,7 @@ func MakeSectorCreateEndpoint(defaultTenantId int64, orgSvc api.OrganizationServ
 			}
 		} else {
 			// If parent sector not set, inherits from root
-			rootSector, err := sectSvc.FindRootSectorId(defaultTenantId, org.Id)
+			rootSector, err := sectSvc.FindRootSectorId(tenantId, org.Id)
 			if err != nil {
 				return err
 			}
@@ -133,7 +195,7 @@ func MakeSectorCreateEndpoint(defaultTenantId int64, orgSvc api.OrganizationServ
 			secModel.Depth = 1
 		}
 
-		_, errCreate := sectSvc.Create(defaultTenantId, secModel)
+		_, errCreate := sectSvc.Create(tenantId, secModel)
 		if errCreate != nil {
 			if errCreate.Error() == dtos.SectorAlreadyExist {
 				_ = ctx.SendStatus(fiber.StatusConflict)
@@ -152,16 +214,208 @@ func MakeSectorCreateEndpoint(defaultTenantId int64, orgSvc api.OrganizationServ
 	}
 }
 
-func MakeSectorDeleteEndpoint(defaultTenantId int64, orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeSectorTreeCreateEndpoint(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+		if !helpers.OrgAcceptsChildren(org.Status) {
+			_ = ctx.SendStatus(fiber.StatusConflict)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.OrgNotActive), fiber.StatusConflict)
+			return ctx.JSON(apiErr)
+		}
+
+		treeReq := sectors.CreateSectorTreeReq{}
+		if err := ctx.BodyParser(&treeReq); err != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(err, fiber.StatusBadRequest)
+			return ctx.JSON(apiErr)
+		}
+		var errorsList []validation.ErrorValidation
+		errValid := validate.Struct(treeReq)
+		if errValid != nil {
+			errorsList = validation.ConvertValidationErrors(errValid)
+		}
+		errorsList = append(errorsList, sectors.ValidateSectorTreeNodes("sectors", treeReq.Sectors)...)
+		defs, errSchema := sectSvc.FindAttributeSchema(tenantId, org.Id)
+		if errSchema != nil {
+			return sendSectorError(ctx, errSchema)
+		}
+		var validateAttributes func(prefix string, nodes []sectors.SectorTreeNodeReq)
+		validateAttributes = func(prefix string, nodes []sectors.SectorTreeNodeReq) {
+			for inc, node := range nodes {
+				nodePrefix := fmt.Sprintf("%s[%d]", prefix, inc)
+				for _, errAttribute := range helpers.ValidateSectorAttributes(defs, node.Attributes) {
+					errAttribute.Field = nodePrefix + "." + errAttribute.Field
+					errorsList = append(errorsList, errAttribute)
+				}
+				validateAttributes(nodePrefix+".children", node.Children)
+			}
+		}
+		validateAttributes("sectors", treeReq.Sectors)
+		if len(errorsList) > 0 {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			return ctx.JSON(exceptions.ConvertValidationError(errorsList))
+		}
+
+		parentCode := treeReq.ParentCode
+		if treeReq.ParentPath != "" {
+			if parentCode != "" {
+				return sendSectorError(ctx, errors.New(dtos.SectorParentAmbiguous))
+			}
+			parent, errParent := sectSvc.FindByLabelPath(tenantId, org.Id, treeReq.ParentPath)
+			if errParent != nil {
+				return sendSectorError(ctx, errParent)
+			}
+			parentCode = parent.Code
+		}
+
+		nodes, errCreate := sectSvc.CreateTree(tenantId, org.Id, parentCode, converters.ConvertSectorTreeReqToModel(treeReq.Sectors))
+		if errCreate != nil {
+			return sendSectorError(ctx, errCreate)
+		}
+		_ = ctx.SendStatus(fiber.StatusCreated)
+		return ctx.JSON(sectors.SectorTreeListResponse{Sectors: converters.ConvertSectorTreeModelToResp(nodes)})
+	}
+}
+
+func MakeSectorExportEndpoint(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+		format, errorsList := parseSectorFormat(ctx)
+		if len(errorsList) > 0 {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			return ctx.JSON(exceptions.ConvertValidationError(errorsList))
+		}
+
+		if format == model.SectorFormatCsv {
+			sectorsList, errFindAll := sectSvc.FindSectorsByTenantOrg(tenantId, org.Id, nil)
+			if errFindAll != nil {
+				return sendSectorError(ctx, errFindAll)
+			}
+			ctx.Set(fiber.HeaderContentType, "text/csv")
+			ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s-sectors.csv\"", org.Code))
+			return converters.WriteSectorsCsv(ctx, sectorsList)
+		}
+
+		root, errHierarchy := findSectorsHierarchy(sectSvc, tenantId, org.Id)
+		if errHierarchy != nil {
+			return sendSectorError(ctx, errHierarchy)
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(root)
+	}
+}
+
+// MakeSectorDiffEndpoint compares the sectors tree of the organization with the tree of the organization given by the against
+// query parameter or, when absent, with the tree posted in the body
+func MakeSectorDiffEndpoint(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
 
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+		base, errBase := findSectorsHierarchy(sectSvc, tenantId, org.Id)
+		if errBase != nil {
+			return sendSectorError(ctx, errBase)
+		}
+
+		target := sectors.SectorResponse{}
+		if againstCode := ctx.Query("against", ""); againstCode != "" {
+			against, errFindAgainst := orgSvc.FindByCode(tenantId, againstCode)
+			if errFindAgainst != nil {
+				return sendOrgError(ctx, errFindAgainst)
+			}
+			var errTarget error
+			target, errTarget = findSectorsHierarchy(sectSvc, tenantId, against.Id)
+			if errTarget != nil {
+				return sendSectorError(ctx, errTarget)
+			}
+		} else if err := ctx.BodyParser(&target); err != nil || target.Label == "" {
+			return sendSectorError(ctx, errors.New(dtos.SectorDiffBadFormat))
+		}
+
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(converters.ConvertSectorDiffModelToResp(helpers.DiffSectorTrees(base, target)))
+	}
+}
+
+// findSectorsHierarchy nests the live sectors of the organization under its root sector
+func findSectorsHierarchy(sectSvc api.SectorServiceInterface, tenantId int64, orgId int64) (sectors.SectorResponse, error) {
+	sectorsList, errFindAll := sectSvc.FindSectorsByTenantOrg(tenantId, orgId, nil)
+	if errFindAll != nil {
+		return sectors.SectorResponse{}, errFindAll
+	}
+	sectorsResponseList := make([]sectors.SectorResponse, len(sectorsList))
+	for inc, s := range sectorsList {
+		sectorsResponseList[inc] = converters.ConvertSectorModelToSectorResp(s)
+	}
+	return helpers.BuildSectorsHierarchy(sectorsResponseList)
+}
+
+func MakeSectorImportEndpoint(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+		if !helpers.OrgAcceptsChildren(org.Status) {
+			_ = ctx.SendStatus(fiber.StatusConflict)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.OrgNotActive), fiber.StatusConflict)
+			return ctx.JSON(apiErr)
+		}
+		format, errorsList := parseSectorFormat(ctx)
+		if len(errorsList) > 0 {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			return ctx.JSON(exceptions.ConvertValidationError(errorsList))
+		}
+
+		var rows []model.SectorImportRow
+		if format == model.SectorFormatCsv {
+			var errRead error
+			rows, errRead = converters.ReadSectorsCsv(bytes.NewReader(ctx.Body()))
+			if errRead != nil {
+				return sendSectorError(ctx, errRead)
+			}
+		} else {
+			root := sectors.SectorResponse{}
+			if err := ctx.BodyParser(&root); err != nil {
+				return sendSectorError(ctx, errors.New(dtos.SectorImportBadFormat))
+			}
+			rows = converters.ConvertSectorTreeToImportRows(root)
+		}
+
+		report, errImport := sectSvc.Import(tenantId, org.Id, rows)
+		if errImport != nil {
+			return sendSectorError(ctx, errImport)
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(sectors.SectorImportResponse{Created: report.Created, Updated: report.Updated, Moved: report.Moved})
+	}
+}
+
+func MakeSectorDeleteEndpoint(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+
+		tenantId := middlewares.GetTenantId(ctx)
 		var nilOrg model.Organization
-		var nilSector model.Sector
 
 		orgCode := ctx.Params("orgCode")
 
 		// Ensure organization exists
-		org, errFindOrga := orgSvc.FindByCode(defaultTenantId, orgCode)
+		org, errFindOrga := orgSvc.FindByCode(tenantId, orgCode)
 		if errFindOrga != nil {
 			_ = ctx.SendStatus(fiber.StatusInternalServerError)
 			apiErr := exceptions.ConvertToInternalError(errFindOrga)
@@ -175,37 +429,100 @@ func MakeSectorDeleteEndpoint(defaultTenantId int64, orgSvc api.OrganizationServ
 
 		// Ensure sector exists
 		sectorCode := ctx.Params("sectorCode")
-		sector, errSect := sectSvc.FindByCode(defaultTenantId, sectorCode)
-		if errSect != nil {
-			return errSect
+		sector, version, sent, errSend := sectorIfMatch(ctx, sectSvc, tenantId, org.Id, sectorCode)
+		if sent {
+			return errSend
+		}
+
+		options := model.SectorDeleteOptions{
+			Mode:    model.SectorDeleteMode(ctx.Query("mode", string(model.SectorDeleteCascade))),
+			Version: version,
 		}
-		if sector == nilSector || sector.Id <= 0 {
+		report, errDelete := sectSvc.DeleteSector(tenantId, sector, options)
+		if errDelete != nil {
+			return sendSectorError(ctx, errDelete)
+		}
+
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(sectors.SectorDeletionResponse{
+			Mode:        string(options.Mode),
+			Sectors:     report.Sectors,
+			Reparented:  report.Reparented,
+			Assignments: report.Assignments,
+		})
+	}
+}
+
+func MakeSectorUpdateEndpoint(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+		var nilOrg model.Organization
+
+		orgCode := ctx.Params("orgCode")
+
+		// Ensure organization exists
+		org, errFindOrga := orgSvc.FindByCode(tenantId, orgCode)
+		if errFindOrga != nil {
+			_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			apiErr := exceptions.ConvertToInternalError(errFindOrga)
+			return ctx.JSON(apiErr)
+		}
+		if org == nilOrg {
 			_ = ctx.SendStatus(fiber.StatusNotFound)
-			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.SectorNotFound), fiber.StatusNotFound)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.OrgNotFound), fiber.StatusNotFound)
 			return ctx.JSON(apiErr)
 		}
 
-		errDelete := sectSvc.DeleteSector(defaultTenantId, sector.Id)
-		if errDelete != nil {
+		// Ensure sector exists
+		sectorCode := ctx.Params("sectorCode")
+		sector, version, sent, errSend := sectorIfMatch(ctx, sectSvc, tenantId, org.Id, sectorCode)
+		if sent {
+			return errSend
+		}
+
+		payload := sectors.UpdateSectorReq{}
+		if err := ctx.BodyParser(&payload); err != nil {
 			_ = ctx.SendStatus(fiber.StatusInternalServerError)
-			apiErr := exceptions.ConvertToInternalError(errDelete)
+			apiErr := exceptions.ConvertToInternalError(err)
 			return ctx.JSON(apiErr)
 		}
+		errValid := validate.Struct(payload)
+		if errValid != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiError := exceptions.ConvertValidationError(validation.ConvertValidationErrors(errValid))
+			return ctx.JSON(apiError)
+		}
+		if payload.Attributes != nil {
+			errorsList, errAttributes := sectSvc.ValidateAttributes(tenantId, sector.OrgId, payload.Attributes)
+			if errAttributes != nil {
+				return sendSectorError(ctx, errAttributes)
+			}
+			if len(errorsList) > 0 {
+				_ = ctx.SendStatus(fiber.StatusBadRequest)
+				return ctx.JSON(exceptions.ConvertValidationError(errorsList))
+			}
+		}
+
+		errUpdate := sectSvc.Update(tenantId, sector, *payload.Label, payload.Attributes, version)
+		if errUpdate != nil {
+			return sendSectorError(ctx, errUpdate)
+		}
 
 		_ = ctx.SendStatus(fiber.StatusNoContent)
 		return nil
 	}
 }
 
-func MakeSectorUpdateEndpoint(defaultTenantId int64, orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeSectorRestoreEndpoint(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
 		var nilOrg model.Organization
-		var nilSector model.Sector
 
 		orgCode := ctx.Params("orgCode")
 
 		// Ensure organization exists
-		org, errFindOrga := orgSvc.FindByCode(defaultTenantId, orgCode)
+		org, errFindOrga := orgSvc.FindByCode(tenantId, orgCode)
 		if errFindOrga != nil {
 			_ = ctx.SendStatus(fiber.StatusInternalServerError)
 			apiErr := exceptions.ConvertToInternalError(errFindOrga)
@@ -217,48 +534,514 @@ func MakeSectorUpdateEndpoint(defaultTenantId int64, orgSvc api.OrganizationServ
 			return ctx.JSON(apiErr)
 		}
 
-		// Ensure sector exists
-		sectorCode := ctx.Params("sectorCode")
-		sector, errSect := sectSvc.FindByCode(defaultTenantId, sectorCode)
-		if errSect != nil {
-			return errSect
+		errRestore := sectSvc.Restore(tenantId, org.Id, ctx.Params("sectorCode"))
+		if errRestore != nil {
+			switch errRestore.Error() {
+			case dtos.SectorNotFound:
+				_ = ctx.SendStatus(fiber.StatusNotFound)
+				apiErr := exceptions.ConvertToFunctionalError(errRestore, fiber.StatusNotFound)
+				return ctx.JSON(apiErr)
+			case dtos.SectorParentDeleted, dtos.SectorAlreadyExist:
+				_ = ctx.SendStatus(fiber.StatusConflict)
+				apiErr := exceptions.ConvertToFunctionalError(errRestore, fiber.StatusConflict)
+				return ctx.JSON(apiErr)
+			default:
+				_ = ctx.SendStatus(fiber.StatusInternalServerError)
+				apiErr := exceptions.ConvertToInternalError(errRestore)
+				return ctx.JSON(apiErr)
+			}
+		}
+
+		_ = ctx.SendStatus(fiber.StatusNoContent)
+		return nil
+	}
+}
+
+// MakeSectorMemberEndpoint assigns (assign=true) or unassigns a user of the tenant to the sector
+func MakeSectorMemberEndpoint(assign bool, orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
 		}
-		if sector == nilSector || sector.Id <= 0 {
+
+		sector, errSect := sectSvc.FindByCode(tenantId, ctx.Params("sectorCode"))
+		if errors.Is(errSect, pgx.ErrNoRows) || (errSect == nil && sector.OrgId != org.Id) {
 			_ = ctx.SendStatus(fiber.StatusNotFound)
 			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.SectorNotFound), fiber.StatusNotFound)
 			return ctx.JSON(apiErr)
 		}
-
-		payload := struct {
-			Label string `json:"label"`
-		}{}
-		if err := ctx.BodyParser(&payload); err != nil {
+		if errSect != nil {
 			_ = ctx.SendStatus(fiber.StatusInternalServerError)
-			apiErr := exceptions.ConvertToInternalError(err)
+			apiErr := exceptions.ConvertToInternalError(errSect)
 			return ctx.JSON(apiErr)
 		}
 
-		idSec, codeSec, errSec := sectSvc.FindByLabel(defaultTenantId, payload.Label)
-		if errSec != nil {
+		var errMember error
+		if assign {
+			errMember = sectSvc.AddMember(tenantId, sector.Id, ctx.Params("userId"))
+		} else {
+			errMember = sectSvc.RemoveMember(tenantId, sector.Id, ctx.Params("userId"))
+		}
+		if errMember != nil {
+			if errMember.Error() == dtos.UserNotFound {
+				_ = ctx.SendStatus(fiber.StatusNotFound)
+				apiErr := exceptions.ConvertToFunctionalError(errMember, fiber.StatusNotFound)
+				return ctx.JSON(apiErr)
+			}
 			_ = ctx.SendStatus(fiber.StatusInternalServerError)
-			apiErr := exceptions.ConvertToInternalError(errSec)
+			apiErr := exceptions.ConvertToInternalError(errMember)
 			return ctx.JSON(apiErr)
 		}
+		_ = ctx.SendStatus(fiber.StatusNoContent)
+		return nil
+	}
+}
 
-		if idSec > 0 && codeSec != sector.Code {
-			_ = ctx.SendStatus(fiber.StatusConflict)
-			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.SectorAlreadyExist), fiber.StatusConflict)
+func MakeSectorMoveEndpoint(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+		_, version, sent, errSend := sectorIfMatch(ctx, sectSvc, tenantId, org.Id, ctx.Params("sectorCode"))
+		if sent {
+			return errSend
+		}
+
+		moveReq := sectors.MoveSectorReq{}
+		if err := ctx.BodyParser(&moveReq); err != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(err, fiber.StatusBadRequest)
 			return ctx.JSON(apiErr)
 		}
+		errValid := validate.Struct(moveReq)
+		if errValid != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiError := exceptions.ConvertValidationError(validation.ConvertValidationErrors(errValid))
+			return ctx.JSON(apiError)
+		}
 
-		errDelete := sectSvc.Update(defaultTenantId, sector.Id, payload.Label)
-		if errDelete != nil {
-			_ = ctx.SendStatus(fiber.StatusInternalServerError)
-			apiErr := exceptions.ConvertToInternalError(errDelete)
+		errMove := sectSvc.Move(tenantId, org.Id, ctx.Params("sectorCode"), *moveReq.ParentCode, version)
+		if errMove != nil {
+			return sendSectorError(ctx, errMove)
+		}
+		_ = ctx.SendStatus(fiber.StatusNoContent)
+		return nil
+	}
+}
+
+// MakeSectorMergeEndpoint merges the sector into the target sector, dryRun=true previews the merge without writing it
+func MakeSectorMergeEndpoint(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+		_, version, sent, errSend := sectorIfMatch(ctx, sectSvc, tenantId, org.Id, ctx.Params("sectorCode"))
+		if sent {
+			return errSend
+		}
+
+		mergeReq := sectors.MergeSectorReq{}
+		if err := ctx.BodyParser(&mergeReq); err != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(err, fiber.StatusBadRequest)
+			return ctx.JSON(apiErr)
+		}
+		errValid := validate.Struct(mergeReq)
+		if errValid != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiError := exceptions.ConvertValidationError(validation.ConvertValidationErrors(errValid))
+			return ctx.JSON(apiError)
+		}
+
+		options := model.SectorMergeOptions{
+			TargetCode: *mergeReq.TargetCode,
+			DryRun:     ctx.QueryBool("dryRun", false),
+			Version:    version,
+		}
+		report, errMerge := sectSvc.Merge(tenantId, org.Id, ctx.Params("sectorCode"), options)
+		if errMerge != nil {
+			return sendSectorError(ctx, errMerge)
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(sectors.SectorReorganisationResponse{
+			Code:        report.Code,
+			DryRun:      options.DryRun,
+			Children:    report.Children,
+			Assignments: report.Assignments,
+		})
+	}
+}
+
+// MakeSectorSplitEndpoint creates a sibling of the sector holding the chosen children and users, dryRun=true previews the split without writing it
+func MakeSectorSplitEndpoint(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+		_, version, sent, errSend := sectorIfMatch(ctx, sectSvc, tenantId, org.Id, ctx.Params("sectorCode"))
+		if sent {
+			return errSend
+		}
+
+		splitReq := sectors.SplitSectorReq{}
+		if err := ctx.BodyParser(&splitReq); err != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(err, fiber.StatusBadRequest)
 			return ctx.JSON(apiErr)
 		}
+		errValid := validate.Struct(splitReq)
+		if errValid != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiError := exceptions.ConvertValidationError(validation.ConvertValidationErrors(errValid))
+			return ctx.JSON(apiError)
+		}
+
+		options := model.SectorSplitOptions{
+			Code:       uuid.New().String(),
+			Label:      *splitReq.Label,
+			ChildCodes: splitReq.ChildCodes,
+			UserIds:    splitReq.UserIds,
+			DryRun:     ctx.QueryBool("dryRun", false),
+			Version:    version,
+		}
+		report, errSplit := sectSvc.Split(tenantId, org.Id, ctx.Params("sectorCode"), options)
+		if errSplit != nil {
+			return sendSectorError(ctx, errSplit)
+		}
+		status := fiber.StatusCreated
+		if options.DryRun {
+			status = fiber.StatusOK
+		}
+		_ = ctx.SendStatus(status)
+		return ctx.JSON(sectors.SectorReorganisationResponse{
+			Code:        report.Code,
+			DryRun:      options.DryRun,
+			Children:    report.Children,
+			Assignments: report.Assignments,
+		})
+	}
+}
+
+func MakeSectorStatusEndpoint(status model.SectorStatus, orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
 
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+		_, version, sent, errSend := sectorIfMatch(ctx, sectSvc, tenantId, org.Id, ctx.Params("sectorCode"))
+		if sent {
+			return errSend
+		}
+
+		options := model.SectorStatusOptions{
+			Cascade: ctx.QueryBool("cascade", false),
+			Force:   ctx.QueryBool("force", false),
+			Version: version,
+		}
+		errStatus := sectSvc.ChangeStatus(tenantId, org.Id, ctx.Params("sectorCode"), status, options)
+		if errStatus != nil {
+			return sendSectorError(ctx, errStatus)
+		}
 		_ = ctx.SendStatus(fiber.StatusNoContent)
 		return nil
 	}
 }
+
+func MakeSectorReorderEndpoint(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+
+		reorderReq := sectors.ReorderSectorsReq{}
+		if err := ctx.BodyParser(&reorderReq); err != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(err, fiber.StatusBadRequest)
+			return ctx.JSON(apiErr)
+		}
+		errValid := validate.Struct(reorderReq)
+		if errValid != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiError := exceptions.ConvertValidationError(validation.ConvertValidationErrors(errValid))
+			return ctx.JSON(apiError)
+		}
+
+		errReorder := sectSvc.Reorder(tenantId, org.Id, ctx.Params("sectorCode"), reorderReq.Codes)
+		if errReorder != nil {
+			return sendSectorError(ctx, errReorder)
+		}
+		_ = ctx.SendStatus(fiber.StatusNoContent)
+		return nil
+	}
+}
+
+// sectorIfMatch evaluates If-Match against the sector of the organization and returns it with the version its write must expect.
+// When sent is true the response is already sent: 412 when the precondition fails, 404 when the sector does not exist.
+func sectorIfMatch(ctx *fiber.Ctx, sectSvc api.SectorServiceInterface, tenantId int64, orgId int64, sectorCode string) (sector model.Sector, version int64, sent bool, err error) {
+	sector, errSect := sectSvc.FindByCode(tenantId, sectorCode)
+	if errSect != nil && !errors.Is(errSect, pgx.ErrNoRows) {
+		return sector, 0, true, sendSectorError(ctx, errSect)
+	}
+	found := errSect == nil && sector.OrgId == orgId
+	var representationTags []string
+	// The detail GET tags the sector with its subtree and ancestors, that tag is only recomputed when it is sent back
+	if found && strings.Contains(ctx.Get(fiber.HeaderIfMatch), "\"s-") {
+		detailTag, errDetail := sectorDetailETag(sectSvc, tenantId, sector)
+		if errDetail != nil {
+			return sector, 0, true, sendSectorError(ctx, errDetail)
+		}
+		representationTags = append(representationTags, detailTag)
+	}
+	version, versionOk := ifMatchVersion(ctx, sector.Version, found, representationTags...)
+	if !versionOk {
+		return sector, 0, true, sendPreconditionFailed(ctx)
+	}
+	if !found {
+		return sector, 0, true, sendSectorError(ctx, errors.New(dtos.SectorNotFound))
+	}
+	return sector, version, false, nil
+}
+
+// sectorDetailETag returns the entity tag of the sector detail served without maxDepth, covering its whole subtree and its ancestors
+func sectorDetailETag(sectSvc api.SectorServiceInterface, tenantId int64, sector model.Sector) (string, error) {
+	subtree, errSubtree := sectSvc.FindSubtree(tenantId, sector.OrgId, sector.Code, -1)
+	if errSubtree != nil {
+		return "", errSubtree
+	}
+	ancestors, errAncestors := sectSvc.FindAncestors(tenantId, sector.Id)
+	if errAncestors != nil {
+		return "", errAncestors
+	}
+	return sectorsETag(subtree, ancestors), nil
+}
+
+func sendSectorError(ctx *fiber.Ctx, err error) error {
+	var hierarchyErr helpers.SectorHierarchyError
+	if errors.As(err, &hierarchyErr) {
+		details := make([]dtos.ApiErrorDetails, len(hierarchyErr.Issues))
+		for inc, issue := range hierarchyErr.Issues {
+			details[inc] = dtos.ApiErrorDetails{Field: issue.Code, Detail: issue.Reason}
+		}
+		_ = ctx.SendStatus(fiber.StatusInternalServerError)
+		return ctx.JSON(exceptions.ConvertToInternalErrorWithDetails(err, details))
+	}
+	var attributesErr helpers.SectorAttributesError
+	if errors.As(err, &attributesErr) {
+		_ = ctx.SendStatus(fiber.StatusBadRequest)
+		return ctx.JSON(exceptions.ConvertValidationError(attributesErr.Errors))
+	}
+	var pathErr helpers.SectorPathNotFoundError
+	if errors.As(err, &pathErr) {
+		_ = ctx.SendStatus(fiber.StatusNotFound)
+		apiErr := exceptions.ConvertToFunctionalError(err, fiber.StatusNotFound)
+		apiErr.Details = []dtos.ApiErrorDetails{{Field: "path", Detail: pathErr.Segment}}
+		return ctx.JSON(apiErr)
+	}
+	var importErr helpers.SectorImportError
+	if errors.As(err, &importErr) {
+		details := make([]dtos.ApiErrorDetails, len(importErr.Issues))
+		for inc, issue := range importErr.Issues {
+			details[inc] = dtos.ApiErrorDetails{Field: fmt.Sprintf("line %d", issue.Line), Detail: issue.Reason}
+		}
+		_ = ctx.SendStatus(fiber.StatusBadRequest)
+		apiErr := exceptions.ConvertToFunctionalError(err, fiber.StatusBadRequest)
+		apiErr.Details = details
+		return ctx.JSON(apiErr)
+	}
+	switch err.Error() {
+	case dtos.VersionMismatch:
+		return sendPreconditionFailed(ctx)
+	case dtos.SectorNotFound, dtos.SectorParentNotFound, dtos.SectorRootNotFound, dtos.SectorTargetNotFound, dtos.UserNotFound:
+		_ = ctx.SendStatus(fiber.StatusNotFound)
+		return ctx.JSON(exceptions.ConvertToFunctionalError(err, fiber.StatusNotFound))
+	case dtos.SectorMoveRoot, dtos.SectorMoveCrossOrg, dtos.SectorDeleteRoot, dtos.SectorInvalidDeleteMode, dtos.SectorReorderInvalid, dtos.SectorImportBadFormat,
+		dtos.SectorPathInvalid, dtos.SectorParentAmbiguous, dtos.SectorDiffBadFormat,
+		dtos.SectorMergeRoot, dtos.SectorSplitRoot, dtos.SectorSplitInvalid:
+		_ = ctx.SendStatus(fiber.StatusBadRequest)
+		return ctx.JSON(exceptions.ConvertToFunctionalError(err, fiber.StatusBadRequest))
+	case dtos.SectorAlreadyExist, dtos.SectorParentDeleted, dtos.SectorMoveCycle, dtos.SectorParentInactive, dtos.SectorHasActiveMembers,
+		dtos.SectorMergeCycle:
+		_ = ctx.SendStatus(fiber.StatusConflict)
+		return ctx.JSON(exceptions.ConvertToFunctionalError(err, fiber.StatusConflict))
+	default:
+		_ = ctx.SendStatus(fiber.StatusInternalServerError)
+		return ctx.JSON(exceptions.ConvertToInternalError(err))
+	}
+}
+
+func MakeSectorFindByCode(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+		maxDepth, errorsList := parseMaxDepth(ctx)
+		if len(errorsList) > 0 {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			return ctx.JSON(exceptions.ConvertValidationError(errorsList))
+		}
+
+		subtree, errSubtree := sectSvc.FindSubtree(tenantId, org.Id, ctx.Params("sectorCode"), maxDepth)
+		if errSubtree != nil {
+			return sendSectorError(ctx, errSubtree)
+		}
+		// The sector may have been deleted between its lookup and the subtree query
+		if len(subtree) == 0 {
+			return sendSectorError(ctx, errors.New(dtos.SectorNotFound))
+		}
+		sector := subtree[0]
+		ancestors, errAncestors := sectSvc.FindAncestors(tenantId, sector.Id)
+		if errAncestors != nil {
+			return sendSectorError(ctx, errAncestors)
+		}
+		if notModifiedTag(ctx, sectorsETag(subtree, ancestors)) {
+			return ctx.SendStatus(fiber.StatusNotModified)
+		}
+
+		sectorsResponseList := make([]sectors.SectorResponse, len(subtree))
+		for inc, s := range subtree {
+			sectorsResponseList[inc] = converters.ConvertSectorModelToSectorResp(s)
+		}
+		sectorResponse, errHierarchy := helpers.BuildSectorsSubtree(sector.Id, sectorsResponseList)
+		if errHierarchy != nil {
+			return sendSectorError(ctx, errHierarchy)
+		}
+		breadcrumbs := make([]sectors.SectorBreadcrumb, len(ancestors))
+		for inc, ancestor := range ancestors {
+			breadcrumbs[inc] = converters.ConvertSectorModelToBreadcrumb(ancestor)
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(sectors.SectorDetailResponse{Sector: sectorResponse, Ancestors: breadcrumbs})
+	}
+}
+
+func MakeSectorFindByPath(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+		sector, errFind := sectSvc.FindByLabelPath(tenantId, org.Id, ctx.Query("path", ""))
+		if errFind != nil {
+			return sendSectorError(ctx, errFind)
+		}
+		if notModified(ctx, sector.Version) {
+			return ctx.SendStatus(fiber.StatusNotModified)
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(converters.ConvertSectorModelToSectorResp(sector))
+	}
+}
+
+func MakeSectorDescendants(orgSvc api.OrganizationServiceInterface, sectSvc api.SectorServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		org, errFindOrga := orgSvc.FindByCode(tenantId, ctx.Params("orgCode"))
+		if errFindOrga != nil {
+			return sendOrgError(ctx, errFindOrga)
+		}
+		maxDepth, errorsList := parseMaxDepth(ctx)
+		if len(errorsList) > 0 {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			return ctx.JSON(exceptions.ConvertValidationError(errorsList))
+		}
+
+		subtree, errSubtree := sectSvc.FindSubtree(tenantId, org.Id, ctx.Params("sectorCode"), maxDepth)
+		if errSubtree != nil {
+			return sendSectorError(ctx, errSubtree)
+		}
+
+		// Parents are listed before their children, the first sector is the requested one
+		codes := make(map[int64]string, len(subtree))
+		descendants := make([]sectors.SectorFlatResponse, 0, len(subtree))
+		for inc, s := range subtree {
+			codes[s.Id] = s.Code
+			if inc > 0 {
+				descendants = append(descendants, converters.ConvertSectorModelToFlatResp(s, codes[s.ParentId.Int64]))
+			}
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(sectors.SectorFlatListResponse{Sectors: descendants})
+	}
+}
+
+// parseMaxDepth reads the maxDepth query parameter, -1 when absent
+func parseMaxDepth(ctx *fiber.Ctx) (int, []validation.ErrorValidation) {
+	maxDepthStr := ctx.Query("maxDepth", "")
+	if maxDepthStr == "" {
+		return -1, nil
+	}
+	maxDepth, errDepth := strconv.Atoi(maxDepthStr)
+	if errDepth != nil || maxDepth < 0 {
+		return -1, []validation.ErrorValidation{{Field: "maxDepth", Error: fmt.Sprintf(validation.FieldInvalidValue, "maxDepth", maxDepthStr)}}
+	}
+	return maxDepth, nil
+}
+
+// parseSectorStatuses reads the comma separated status query parameter, empty when absent
+func parseSectorStatuses(ctx *fiber.Ctx) ([]model.SectorStatus, []validation.ErrorValidation) {
+	statusStr := ctx.Query("status", "")
+	if statusStr == "" {
+		return nil, nil
+	}
+	var statuses []model.SectorStatus
+	for _, value := range strings.Split(statusStr, ",") {
+		status, errStatus := strconv.Atoi(strings.TrimSpace(value))
+		if errStatus != nil || !helpers.IsSectorStatusValid(model.SectorStatus(status)) {
+			return nil, []validation.ErrorValidation{{Field: "status", Error: fmt.Sprintf(validation.FieldInvalidValue, "status", statusStr)}}
+		}
+		statuses = append(statuses, model.SectorStatus(status))
+	}
+	return statuses, nil
+}
+
+// parseSectorFormat reads the format query parameter, json when absent
+func parseSectorFormat(ctx *fiber.Ctx) (model.SectorExchangeFormat, []validation.ErrorValidation) {
+	format := model.SectorExchangeFormat(ctx.Query("format", string(model.SectorFormatJson)))
+	if format != model.SectorFormatJson && format != model.SectorFormatCsv {
+		return format, []validation.ErrorValidation{{Field: "format", Error: fmt.Sprintf(validation.FieldInvalidValue, "format", string(format))}}
+	}
+	return format, nil
+}
+
+// parseSectorDiagramFormat reads the format query parameter of the sectors tree, empty when absent so that the tree is sent as json
+func parseSectorDiagramFormat(ctx *fiber.Ctx) (model.SectorDiagramFormat, []validation.ErrorValidation) {
+	format := model.SectorDiagramFormat(ctx.Query("format", ""))
+	if format != "" && format != model.SectorDiagramDot && format != model.SectorDiagramMermaid {
+		return "", []validation.ErrorValidation{{Field: "format", Error: fmt.Sprintf(validation.FieldInvalidValue, "format", string(format))}}
+	}
+	return format, nil
+}
+
+// parseAttributeFilters collects the attr.<name>=<value> query parameters
+func parseAttributeFilters(ctx *fiber.Ctx) map[string]string {
+	filters := make(map[string]string)
+	for key, value := range ctx.Queries() {
+		if name, found := strings.CutPrefix(key, "attr."); found && name != "" {
+			filters[name] = value
+		}
+	}
+	return filters
+}
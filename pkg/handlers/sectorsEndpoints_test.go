@@ -0,0 +1,90 @@
+package endpoints
+
+import (
+	"database/sql"
+	"micro-fiber-test/pkg/model"
+	"micro-fiber-test/pkg/service/api"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type orgSvcStub struct {
+	api.OrganizationServiceInterface
+	org model.Organization
+}
+
+func (s orgSvcStub) FindByCode(defautTenantId int64, code string) (model.Organization, error) {
+	return s.org, nil
+}
+
+// sectorSvcStub serves a sector with one child below the root and records the version its updates expect
+type sectorSvcStub struct {
+	api.SectorServiceInterface
+	sectors         []model.Sector
+	updatedVersions []int64
+}
+
+func (s *sectorSvcStub) FindByCode(defaultTenantId int64, code string) (model.Sector, error) {
+	for _, sector := range s.sectors {
+		if sector.Code == code {
+			return sector, nil
+		}
+	}
+	return model.Sector{}, pgx.ErrNoRows
+}
+
+func (s *sectorSvcStub) FindSubtree(defaultTenantId int64, orgId int64, code string, maxDepth int) ([]model.Sector, error) {
+	return s.sectors[1:], nil
+}
+
+func (s *sectorSvcStub) FindAncestors(defaultTenantId int64, sectorId int64) ([]model.Sector, error) {
+	return s.sectors[:1], nil
+}
+
+func (s *sectorSvcStub) Update(defaultTenantId int64, sector model.Sector, label string, attributes map[string]any, version int64) error {
+	s.updatedVersions = append(s.updatedVersions, version)
+	return nil
+}
+
+func TestSectorIfMatchDetailETag(t *testing.T) {
+	sectSvc := &sectorSvcStub{sectors: []model.Sector{
+		{Id: 10, OrgId: 1, Code: "root", Label: "Root", Path: "/10/", Version: 1},
+		{Id: 11, OrgId: 1, Code: "sales", Label: "Sales", Path: "/10/11/", Depth: 1, HasParent: true, ParentId: sql.NullInt64{Int64: 10, Valid: true}, Version: 4},
+		{Id: 12, OrgId: 1, Code: "north", Label: "North", Path: "/10/11/12/", Depth: 2, HasParent: true, ParentId: sql.NullInt64{Int64: 11, Valid: true}, Version: 2},
+	}}
+	orgSvc := orgSvcStub{org: model.Organization{Id: 1, Code: "acme"}}
+	app := fiber.New()
+	app.Get("/orgs/:orgCode/sectors/:sectorCode", MakeSectorFindByCode(orgSvc, sectSvc))
+	app.Put("/orgs/:orgCode/sectors/:sectorCode", MakeSectorUpdateEndpoint(orgSvc, sectSvc))
+	update := func(ifMatch string) int {
+		req := httptest.NewRequest(fiber.MethodPut, "/orgs/acme/sectors/sales", strings.NewReader(`{"label":"Sales EMEA"}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		return resp.StatusCode
+	}
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/orgs/acme/sectors/sales", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get(fiber.HeaderETag)
+	assert.True(t, strings.HasPrefix(etag, `"s-`))
+
+	// The tag of the detail GET sent back as is lets the update expect the version it was read in
+	assert.Equal(t, fiber.StatusNoContent, update(etag))
+	assert.Equal(t, fiber.StatusNoContent, update(`"1", `+etag))
+	assert.Equal(t, fiber.StatusNoContent, update(`"4"`))
+	assert.Equal(t, []int64{4, 4, 4}, sectSvc.updatedVersions)
+
+	// A change in the subtree makes the detail tag stale
+	sectSvc.sectors[2].Version++
+	assert.Equal(t, fiber.StatusPreconditionFailed, update(etag))
+	assert.Equal(t, fiber.StatusPreconditionFailed, update(`W/`+etag))
+	assert.Len(t, sectSvc.updatedVersions, 3)
+}
@@ -0,0 +1,103 @@
+package endpoints
+
+import (
+	"errors"
+	"micro-fiber-test/pkg/converters"
+	dtos "micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/dto/templates"
+	"micro-fiber-test/pkg/exceptions"
+	"micro-fiber-test/pkg/middlewares"
+	"micro-fiber-test/pkg/service/api"
+	"micro-fiber-test/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func MakeTemplateCreateEndpoint(templateSvc api.TemplateServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+		templateReq := templates.CreateTemplateReq{}
+		if err := ctx.BodyParser(&templateReq); err != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(dtos.TemplateInvalidPayload), fiber.StatusBadRequest)
+			return ctx.JSON(apiErr)
+		}
+
+		var errorsList []validation.ErrorValidation
+		errValid := validate.Struct(templateReq)
+		if errValid != nil {
+			errorsList = validation.ConvertValidationErrors(errValid)
+		}
+		errorsList = append(errorsList, templates.ValidateTemplateNodes("sectors", templateReq.Sectors)...)
+		if len(errorsList) > 0 {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiError := exceptions.ConvertValidationError(errorsList)
+			return ctx.JSON(apiError)
+		}
+
+		template := converters.ConvertTemplateReqToDaoModel(tenantId, templateReq)
+		template.Code = uuid.New().String()
+		_, errCreate := templateSvc.Create(tenantId, template)
+		if errCreate != nil {
+			return sendTemplateError(ctx, errCreate)
+		}
+		_ = ctx.SendStatus(fiber.StatusCreated)
+		return ctx.JSON(dtos.CodeResponse{Code: template.Code})
+	}
+}
+
+func MakeTemplateFindAll(templateSvc api.TemplateServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+		templatesList, errFindAll := templateSvc.FindAll(tenantId)
+		if errFindAll != nil {
+			_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			apiErr := exceptions.ConvertToInternalError(errFindAll)
+			return ctx.JSON(apiErr)
+		}
+		templateResponseList := make([]templates.TemplateResponse, len(templatesList))
+		for inc, t := range templatesList {
+			templateResponseList[inc] = converters.ConvertTemplateModelToTemplateResp(t)
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(templates.TemplateListResponse{Templates: templateResponseList})
+	}
+}
+
+func MakeTemplateFindByCode(templateSvc api.TemplateServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+		template, errFind := templateSvc.FindByCode(tenantId, ctx.Params("templateCode"))
+		if errFind != nil {
+			return sendTemplateError(ctx, errFind)
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(converters.ConvertTemplateModelToTemplateResp(template))
+	}
+}
+
+func MakeTemplateDeleteEndpoint(templateSvc api.TemplateServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+		errDelete := templateSvc.Delete(tenantId, ctx.Params("templateCode"))
+		if errDelete != nil {
+			return sendTemplateError(ctx, errDelete)
+		}
+		return ctx.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+func sendTemplateError(ctx *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case dtos.TemplateNotFound:
+		_ = ctx.SendStatus(fiber.StatusNotFound)
+		return ctx.JSON(exceptions.ConvertToFunctionalError(err, fiber.StatusNotFound))
+	case dtos.TemplateAlreadyExists:
+		_ = ctx.SendStatus(fiber.StatusConflict)
+		return ctx.JSON(exceptions.ConvertToFunctionalError(err, fiber.StatusConflict))
+	default:
+		_ = ctx.SendStatus(fiber.StatusInternalServerError)
+		return ctx.JSON(exceptions.ConvertToInternalError(err))
+	}
+}
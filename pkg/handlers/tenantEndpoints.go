@@ -0,0 +1,134 @@
+package endpoints
+
+import (
+	"micro-fiber-test/pkg/converters"
+	dtos "micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/dto/tenants"
+	"micro-fiber-test/pkg/exceptions"
+	"micro-fiber-test/pkg/service/api"
+	"micro-fiber-test/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func MakeTenantCreateEndpoint(tenantSvc api.TenantServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantReq := tenants.CreateTenantReq{}
+		if err := ctx.BodyParser(&tenantReq); err != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(err, fiber.StatusBadRequest)
+			return ctx.JSON(apiErr)
+		}
+
+		errValid := validate.Struct(tenantReq)
+		if errValid != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiError := exceptions.ConvertValidationError(validation.ConvertValidationErrors(errValid))
+			return ctx.JSON(apiError)
+		}
+
+		tenant := converters.ConvertTenantReqToDaoModel(tenantReq)
+		_, errCreate := tenantSvc.Create(tenant)
+		if errCreate != nil {
+			return sendTenantError(ctx, errCreate)
+		}
+		_ = ctx.SendStatus(fiber.StatusCreated)
+		return ctx.JSON(dtos.CodeResponse{Code: tenant.Code})
+	}
+}
+
+func MakeTenantFindAll(tenantSvc api.TenantServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantsList, errFindAll := tenantSvc.FindAll()
+		if errFindAll != nil {
+			_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			apiErr := exceptions.ConvertToInternalError(errFindAll)
+			return ctx.JSON(apiErr)
+		}
+		tenantResponseList := make([]tenants.TenantResponse, len(tenantsList))
+		for inc, t := range tenantsList {
+			tenantResponseList[inc] = converters.ConvertTenantModelToTenantResp(t)
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(tenants.TenantListResponse{Tenants: tenantResponseList})
+	}
+}
+
+func MakeTenantFindByCode(tenantSvc api.TenantServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenant, errFind := tenantSvc.FindByCode(ctx.Params("tenantCode"))
+		if errFind != nil {
+			return sendTenantError(ctx, errFind)
+		}
+		_ = ctx.SendStatus(fiber.StatusOK)
+		return ctx.JSON(converters.ConvertTenantModelToTenantResp(tenant))
+	}
+}
+
+func MakeTenantRenameEndpoint(tenantSvc api.TenantServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantReq := tenants.UpdateTenantReq{}
+		if err := ctx.BodyParser(&tenantReq); err != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(err, fiber.StatusBadRequest)
+			return ctx.JSON(apiErr)
+		}
+
+		errValid := validate.Struct(tenantReq)
+		if errValid != nil {
+			_ = ctx.SendStatus(fiber.StatusBadRequest)
+			apiError := exceptions.ConvertValidationError(validation.ConvertValidationErrors(errValid))
+			return ctx.JSON(apiError)
+		}
+
+		errRename := tenantSvc.Rename(ctx.Params("tenantCode"), *tenantReq.Label)
+		if errRename != nil {
+			return sendTenantError(ctx, errRename)
+		}
+		return ctx.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+func MakeTenantSuspendEndpoint(tenantSvc api.TenantServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		errSuspend := tenantSvc.Suspend(ctx.Params("tenantCode"))
+		if errSuspend != nil {
+			return sendTenantError(ctx, errSuspend)
+		}
+		return ctx.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+func MakeTenantActivateEndpoint(tenantSvc api.TenantServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		errActivate := tenantSvc.Activate(ctx.Params("tenantCode"))
+		if errActivate != nil {
+			return sendTenantError(ctx, errActivate)
+		}
+		return ctx.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+func MakeTenantDeleteEndpoint(tenantSvc api.TenantServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		errDelete := tenantSvc.Delete(ctx.Params("tenantCode"))
+		if errDelete != nil {
+			return sendTenantError(ctx, errDelete)
+		}
+		return ctx.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+func sendTenantError(ctx *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case dtos.TenantNotFound:
+		_ = ctx.SendStatus(fiber.StatusNotFound)
+		return ctx.JSON(exceptions.ConvertToFunctionalError(err, fiber.StatusNotFound))
+	case dtos.TenantAlreadyExists, dtos.TenantNotEmpty:
+		_ = ctx.SendStatus(fiber.StatusConflict)
+		return ctx.JSON(exceptions.ConvertToFunctionalError(err, fiber.StatusConflict))
+	default:
+		_ = ctx.SendStatus(fiber.StatusInternalServerError)
+		return ctx.JSON(exceptions.ConvertToInternalError(err))
+	}
+}
@@ -6,6 +6,8 @@ import (
 	commonsDto "micro-fiber-test/pkg/dto/commons"
 	"micro-fiber-test/pkg/dto/users"
 	"micro-fiber-test/pkg/exceptions"
+	"micro-fiber-test/pkg/helpers"
+	"micro-fiber-test/pkg/middlewares"
 	"micro-fiber-test/pkg/model"
 	"micro-fiber-test/pkg/service/api"
 	"micro-fiber-test/pkg/validation"
@@ -13,17 +15,19 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
-func MakeUserCreateEndpoint(defaultTenantId int64, userSvc api.UserServiceInterface, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeUserCreateEndpoint(userSvc api.UserServiceInterface, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
 
+		tenantId := middlewares.GetTenantId(ctx)
 		var nilOrg model.Organization
 
 		orgCode := ctx.Params("orgCode")
 
 		// Ensure organization exists
-		org, errFindOrga := orgSvc.FindByCode(defaultTenantId, orgCode)
+		org, errFindOrga := orgSvc.FindByCode(tenantId, orgCode)
 		if errFindOrga != nil {
 			_ = ctx.SendStatus(fiber.StatusInternalServerError)
 			apiErr := exceptions.ConvertToInternalError(errFindOrga)
@@ -34,6 +38,11 @@ func MakeUserCreateEndpoint(defaultTenantId int64, userSvc api.UserServiceInterf
 			apiErr := exceptions.ConvertToFunctionalError(errors.New(commonsDto.OrgNotFound), fiber.StatusNotFound)
 			return ctx.JSON(apiErr)
 		}
+		if !helpers.OrgAcceptsChildren(org.Status) {
+			_ = ctx.SendStatus(fiber.StatusConflict)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(commonsDto.OrgNotActive), fiber.StatusConflict)
+			return ctx.JSON(apiErr)
+		}
 
 		// Deserialize request
 		userReq := users.CreateUserReq{}
@@ -51,11 +60,11 @@ func MakeUserCreateEndpoint(defaultTenantId int64, userSvc api.UserServiceInterf
 			return ctx.JSON(apiError)
 		}
 
-		usrModel := converters.ConvertUserReqToDaoModel(defaultTenantId, userReq)
+		usrModel := converters.ConvertUserReqToDaoModel(tenantId, userReq)
 		usrModel.OrgId = org.Id
 		extUUID := uuid.New().String()
 		usrModel.ExternalId = extUUID
-		_, errCreate := userSvc.Create(defaultTenantId, usrModel)
+		_, errCreate := userSvc.Create(tenantId, usrModel)
 		if errCreate != nil {
 			if errCreate.Error() == commonsDto.UserLoginAlreadyInUse || errCreate.Error() == commonsDto.UserEmailAlreadyInUse {
 				apiError := exceptions.ConvertToFunctionalError(errCreate, fiber.StatusConflict)
@@ -73,12 +82,13 @@ func MakeUserCreateEndpoint(defaultTenantId int64, userSvc api.UserServiceInterf
 	}
 }
 
-func MakeUserSearchFilter(defaultTenantId int64, userSvc api.UserServiceInterface, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeUserSearchFilter(userSvc api.UserServiceInterface, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
 		orgCode := ctx.Params("orgCode")
 
 		// Ensure organization exists
-		org, errFindOrga := orgSvc.FindByCode(defaultTenantId, orgCode)
+		org, errFindOrga := orgSvc.FindByCode(tenantId, orgCode)
 		if errFindOrga != nil {
 			if errFindOrga.Error() == commonsDto.OrgDoesNotExistByCode {
 				_ = ctx.SendStatus(fiber.StatusNotFound)
@@ -121,21 +131,20 @@ func MakeUserSearchFilter(defaultTenantId int64, userSvc api.UserServiceInterfac
 			Users:      usersArray,
 			Pagination: pageResp,
 		}
-
-		_ = ctx.SendStatus(fiber.StatusOK)
-		return ctx.JSON(userListReponse)
+		return sendTaggedJSON(ctx, userListReponse)
 	}
 }
 
-func MakeUserFindByCode(defaultTenantId int64, userSvc api.UserServiceInterface, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeUserFindByCode(userSvc api.UserServiceInterface, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
 		var nilOrg model.Organization
 		var nilUser model.User
 
 		orgCode := ctx.Params("orgCode")
 
 		// Ensure organization exists
-		org, errFindOrga := orgSvc.FindByCode(defaultTenantId, orgCode)
+		org, errFindOrga := orgSvc.FindByCode(tenantId, orgCode)
 		if errFindOrga != nil {
 			_ = ctx.SendStatus(fiber.StatusInternalServerError)
 			apiErr := exceptions.ConvertToInternalError(errFindOrga)
@@ -148,11 +157,14 @@ func MakeUserFindByCode(defaultTenantId int64, userSvc api.UserServiceInterface,
 		}
 
 		usrId := ctx.Params("userId")
-		u, errFind := userSvc.FindByCode(defaultTenantId, org.Id, usrId)
+		u, errFind := userSvc.FindByCode(tenantId, org.Id, usrId)
 		if errFind != nil {
 			return errFind
 		}
 		if u != nilUser {
+			if notModified(ctx, u.Version) {
+				return ctx.SendStatus(fiber.StatusNotModified)
+			}
 			_ = ctx.SendStatus(fiber.StatusOK)
 			return ctx.JSON(converters.ConvertFromDaoModelToUserResponse(u))
 		} else {
@@ -162,16 +174,17 @@ func MakeUserFindByCode(defaultTenantId int64, userSvc api.UserServiceInterface,
 	}
 }
 
-func MakeUserDelete(defaultTenantId int64, userSvc api.UserServiceInterface, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeUserDelete(userSvc api.UserServiceInterface, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
 
+		tenantId := middlewares.GetTenantId(ctx)
 		var nilOrg model.Organization
 		var nilUser model.User
 
 		orgCode := ctx.Params("orgCode")
 
 		// Ensure organization exists
-		org, errFindOrga := orgSvc.FindByCode(defaultTenantId, orgCode)
+		org, errFindOrga := orgSvc.FindByCode(tenantId, orgCode)
 		if errFindOrga != nil {
 			_ = ctx.SendStatus(fiber.StatusInternalServerError)
 			apiErr := exceptions.ConvertToInternalError(errFindOrga)
@@ -184,18 +197,25 @@ func MakeUserDelete(defaultTenantId int64, userSvc api.UserServiceInterface, org
 		}
 
 		usrId := ctx.Params("userId")
-		u, errFind := userSvc.FindByCode(defaultTenantId, org.Id, usrId)
-		if errFind != nil {
+		u, errFind := userSvc.FindByCode(tenantId, org.Id, usrId)
+		if errFind != nil && !errors.Is(errFind, pgx.ErrNoRows) {
 			return errFind
 		}
+		version, versionOk := ifMatchVersion(ctx, u.Version, u != nilUser)
+		if !versionOk {
+			return sendPreconditionFailed(ctx)
+		}
 		if u == nilUser {
 			_ = ctx.SendStatus(fiber.StatusNotFound)
 			apiErr := exceptions.ConvertToFunctionalError(errors.New(commonsDto.UserNotFound), fiber.StatusNotFound)
 			return ctx.JSON(apiErr)
 		}
 
-		errDel := userSvc.Delete(usrId)
+		errDel := userSvc.Delete(tenantId, usrId, version)
 		if errDel != nil {
+			if errDel.Error() == commonsDto.VersionMismatch {
+				return sendPreconditionFailed(ctx)
+			}
 			_ = ctx.SendStatus(fiber.StatusInternalServerError)
 			apiErr := exceptions.ConvertToInternalError(errFindOrga)
 			return ctx.JSON(apiErr)
@@ -205,16 +225,17 @@ func MakeUserDelete(defaultTenantId int64, userSvc api.UserServiceInterface, org
 	}
 }
 
-func MakeUserUpdate(defaultTenantId int64, userSvc api.UserServiceInterface, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+func MakeUserUpdate(userSvc api.UserServiceInterface, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
 
+		tenantId := middlewares.GetTenantId(ctx)
 		var nilOrg model.Organization
 		var nilUser model.User
 
 		orgCode := ctx.Params("orgCode")
 
 		// Ensure organization exists
-		org, errFindOrga := orgSvc.FindByCode(defaultTenantId, orgCode)
+		org, errFindOrga := orgSvc.FindByCode(tenantId, orgCode)
 		if errFindOrga != nil {
 			_ = ctx.SendStatus(fiber.StatusInternalServerError)
 			apiErr := exceptions.ConvertToInternalError(errFindOrga)
@@ -227,10 +248,14 @@ func MakeUserUpdate(defaultTenantId int64, userSvc api.UserServiceInterface, org
 		}
 
 		usrId := ctx.Params("userId")
-		u, errFind := userSvc.FindByCode(defaultTenantId, org.Id, usrId)
-		if errFind != nil {
+		u, errFind := userSvc.FindByCode(tenantId, org.Id, usrId)
+		if errFind != nil && !errors.Is(errFind, pgx.ErrNoRows) {
 			return errFind
 		}
+		version, versionOk := ifMatchVersion(ctx, u.Version, u != nilUser)
+		if !versionOk {
+			return sendPreconditionFailed(ctx)
+		}
 
 		if u == nilUser {
 			apiError := exceptions.ConvertToFunctionalError(errors.New(commonsDto.UserNotFound), fiber.StatusNotFound)
@@ -254,13 +279,16 @@ func MakeUserUpdate(defaultTenantId int64, userSvc api.UserServiceInterface, org
 			return ctx.JSON(apiError)
 		}
 
-		usrModel := converters.ConvertUserUpdateReqToDaoModel(defaultTenantId, userReq)
+		usrModel := converters.ConvertUserUpdateReqToDaoModel(tenantId, userReq)
 		usrModel.OrgId = org.Id
 		usrModel.ExternalId = usrId
+		usrModel.Version = version
 
 		errUpdate := userSvc.Update(usrModel)
 		if errUpdate != nil {
-			if errUpdate.Error() == commonsDto.UserLoginAlreadyInUse || errUpdate.Error() == commonsDto.UserEmailAlreadyInUse {
+			if errUpdate.Error() == commonsDto.VersionMismatch {
+				return sendPreconditionFailed(ctx)
+			} else if errUpdate.Error() == commonsDto.UserLoginAlreadyInUse || errUpdate.Error() == commonsDto.UserEmailAlreadyInUse {
 				apiError := exceptions.ConvertToFunctionalError(errUpdate, fiber.StatusConflict)
 				_ = ctx.SendStatus(fiber.StatusConflict)
 				return ctx.JSON(apiError)
@@ -275,6 +303,42 @@ func MakeUserUpdate(defaultTenantId int64, userSvc api.UserServiceInterface, org
 	}
 }
 
+func MakeUserRestore(userSvc api.UserServiceInterface, orgSvc api.OrganizationServiceInterface) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		tenantId := middlewares.GetTenantId(ctx)
+
+		var nilOrg model.Organization
+
+		orgCode := ctx.Params("orgCode")
+
+		// Ensure organization exists
+		org, errFindOrga := orgSvc.FindByCode(tenantId, orgCode)
+		if errFindOrga != nil {
+			_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			apiErr := exceptions.ConvertToInternalError(errFindOrga)
+			return ctx.JSON(apiErr)
+		}
+		if org == nilOrg {
+			_ = ctx.SendStatus(fiber.StatusNotFound)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(commonsDto.OrgNotFound), fiber.StatusNotFound)
+			return ctx.JSON(apiErr)
+		}
+
+		errRestore := userSvc.Restore(tenantId, org.Id, ctx.Params("userId"))
+		if errRestore != nil {
+			if errRestore.Error() == commonsDto.UserNotFound {
+				_ = ctx.SendStatus(fiber.StatusNotFound)
+				apiErr := exceptions.ConvertToFunctionalError(errRestore, fiber.StatusNotFound)
+				return ctx.JSON(apiErr)
+			}
+			_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			apiErr := exceptions.ConvertToInternalError(errRestore)
+			return ctx.JSON(apiErr)
+		}
+		return ctx.SendStatus(fiber.StatusNoContent)
+	}
+}
+
 func buildCriteria(org model.Organization, ctx *fiber.Ctx) (model.UserFilterCriteria, error) {
 	userFilterCriteria := model.UserFilterCriteria{}
 	userFilterCriteria.OrgId = org.Id
@@ -0,0 +1,54 @@
+package helpers
+
+import (
+	"micro-fiber-test/pkg/dto/orgs"
+	"micro-fiber-test/pkg/model"
+)
+
+// orgParentTypes lists, for each organization type, the types allowed as parent. Enterprises are always top level.
+var orgParentTypes = map[model.OrganizationType][]model.OrganizationType{
+	model.OrgTypeEnterprise: {},
+	model.OrgTypeBu:         {model.OrgTypeEnterprise},
+	model.OrgTypeLxsi:       {model.OrgTypeEnterprise, model.OrgTypeBu},
+	model.OrgTypeCommunity:  {model.OrgTypeEnterprise, model.OrgTypeBu},
+}
+
+func IsOrgParentTypeAllowed(kind model.OrganizationType, parentKind model.OrganizationType) bool {
+	for _, allowed := range orgParentTypes[kind] {
+		if allowed == parentKind {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildOrgTree nests organizations under their parent, organizations whose parent is not in the list are returned as roots
+func BuildOrgTree(organizations []orgs.OrgTreeResponse) []orgs.OrgTreeResponse {
+	children := make(map[string][]int, len(organizations))
+	known := make(map[string]bool, len(organizations))
+	for _, org := range organizations {
+		known[org.Code] = true
+	}
+	var roots []int
+	for inc, org := range organizations {
+		if org.ParentCode != "" && known[org.ParentCode] {
+			children[org.ParentCode] = append(children[org.ParentCode], inc)
+		} else {
+			roots = append(roots, inc)
+		}
+	}
+
+	var build func(inc int) orgs.OrgTreeResponse
+	build = func(inc int) orgs.OrgTreeResponse {
+		node := organizations[inc]
+		for _, child := range children[node.Code] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+	tree := make([]orgs.OrgTreeResponse, len(roots))
+	for inc, root := range roots {
+		tree[inc] = build(root)
+	}
+	return tree
+}
@@ -0,0 +1,27 @@
+package helpers
+
+import (
+	"micro-fiber-test/pkg/dto/orgs"
+	"micro-fiber-test/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgHierarchy(t *testing.T) {
+	assert.True(t, IsOrgParentTypeAllowed(model.OrgTypeBu, model.OrgTypeEnterprise))
+	assert.False(t, IsOrgParentTypeAllowed(model.OrgTypeBu, model.OrgTypeCommunity))
+	assert.False(t, IsOrgParentTypeAllowed(model.OrgTypeEnterprise, model.OrgTypeEnterprise))
+
+	tree := BuildOrgTree([]orgs.OrgTreeResponse{
+		{Code: "bu1", ParentCode: "ent"},
+		{Code: "ent"},
+		{Code: "com1", ParentCode: "bu1"},
+		{Code: "orphan", ParentCode: "unknown"},
+	})
+	assert.Equal(t, 2, len(tree))
+	assert.Equal(t, "ent", tree[0].Code)
+	assert.Equal(t, "bu1", tree[0].Children[0].Code)
+	assert.Equal(t, "com1", tree[0].Children[0].Children[0].Code)
+	assert.Equal(t, "orphan", tree[1].Code)
+}
@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"errors"
+	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/model"
+)
+
+// orgTransitions lists, for each lifecycle action, the allowed source statuses and their target status
+var orgTransitions = map[model.OrgLifecycleAction]map[model.OrganizationStatus]model.OrganizationStatus{
+	model.OrgActionActivate: {
+		model.OrgStatusDraft:    model.OrgStatusActive,
+		model.OrgStatusInactive: model.OrgStatusActive,
+	},
+	model.OrgActionDeactivate: {
+		model.OrgStatusActive: model.OrgStatusInactive,
+	},
+	model.OrgActionArchive: {
+		model.OrgStatusDraft:    model.OrgStatusArchived,
+		model.OrgStatusActive:   model.OrgStatusArchived,
+		model.OrgStatusInactive: model.OrgStatusArchived,
+	},
+	model.OrgActionRestore: {
+		model.OrgStatusArchived: model.OrgStatusInactive,
+		model.OrgStatusDeleted:  model.OrgStatusInactive,
+	},
+}
+
+// NextOrgStatus returns the status reached by applying action to an organization in status current
+func NextOrgStatus(action model.OrgLifecycleAction, current model.OrganizationStatus) (model.OrganizationStatus, error) {
+	targetStatus, ok := orgTransitions[action][current]
+	if !ok {
+		return current, errors.New(commons.OrgIllegalTransition)
+	}
+	return targetStatus, nil
+}
+
+// IsOrgTransitionAllowed tells if any lifecycle action moves an organization from status "from" to status "to"
+func IsOrgTransitionAllowed(from model.OrganizationStatus, to model.OrganizationStatus) bool {
+	for _, transitions := range orgTransitions {
+		if target, ok := transitions[from]; ok && target == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOrgInitialStatus tells if an organization can be created in the given status, other statuses are only reached through lifecycle actions
+func IsOrgInitialStatus(status model.OrganizationStatus) bool {
+	return status == model.OrgStatusDraft || status == model.OrgStatusActive
+}
+
+// IsOrgTypeValid tells if kind is one of the supported organization types
+func IsOrgTypeValid(kind model.OrganizationType) bool {
+	switch kind {
+	case model.OrgTypeLxsi, model.OrgTypeBu, model.OrgTypeCommunity, model.OrgTypeEnterprise:
+		return true
+	default:
+		return false
+	}
+}
+
+// OrgAcceptsChildren tells if sectors and users can be added to an organization in the given status
+func OrgAcceptsChildren(status model.OrganizationStatus) bool {
+	return status == model.OrgStatusDraft || status == model.OrgStatusActive
+}
@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"micro-fiber-test/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgLifecycle(t *testing.T) {
+	status, err := NextOrgStatus(model.OrgActionActivate, model.OrgStatusDraft)
+	assert.Nil(t, err)
+	assert.Equal(t, model.OrgStatusActive, status)
+
+	status, err = NextOrgStatus(model.OrgActionArchive, status)
+	assert.Nil(t, err)
+	assert.Equal(t, model.OrgStatusArchived, status)
+
+	_, err = NextOrgStatus(model.OrgActionDeactivate, status)
+	assert.NotNil(t, err)
+
+	status, err = NextOrgStatus(model.OrgActionRestore, status)
+	assert.Nil(t, err)
+	assert.Equal(t, model.OrgStatusInactive, status)
+	assert.False(t, OrgAcceptsChildren(status))
+
+	assert.True(t, IsOrgTransitionAllowed(model.OrgStatusActive, model.OrgStatusInactive))
+	assert.False(t, IsOrgTransitionAllowed(model.OrgStatusDraft, model.OrgStatusInactive))
+	assert.True(t, IsOrgInitialStatus(model.OrgStatusDraft))
+	assert.False(t, IsOrgInitialStatus(model.OrgStatusDeleted))
+	assert.True(t, IsOrgTypeValid(model.OrgTypeBu))
+	assert.False(t, IsOrgTypeValid("holding"))
+}
@@ -0,0 +1,151 @@
+package helpers
+
+import (
+	"fmt"
+	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/model"
+	"micro-fiber-test/pkg/validation"
+	"strconv"
+	"strings"
+)
+
+// SectorAttributesError lists the attributes of a sector that do not match the schema of its organization
+type SectorAttributesError struct {
+	Errors []validation.ErrorValidation
+}
+
+func (e SectorAttributesError) Error() string {
+	return commons.SectorAttributesInvalid
+}
+
+// ValidateSectorAttributeSchema checks the attribute definitions of an organization, fields are reported with their path (attributes[0].name)
+func ValidateSectorAttributeSchema(defs []model.SectorAttributeDef) []validation.ErrorValidation {
+	var errorsList []validation.ErrorValidation
+	names := make(map[string]bool, len(defs))
+	for inc, def := range defs {
+		nameField := fmt.Sprintf("attributes[%d].name", inc)
+		if def.Name == "" {
+			errorsList = append(errorsList, validation.ErrorValidation{Field: nameField, Error: fmt.Sprintf(validation.FieldRequired, nameField)})
+		} else if len(def.Name) > 50 {
+			errorsList = append(errorsList, validation.ErrorValidation{Field: nameField, Error: fmt.Sprintf(validation.FieldMaxLength, nameField, "50")})
+		} else if names[def.Name] {
+			errorsList = append(errorsList, validation.ErrorValidation{Field: nameField, Error: fmt.Sprintf(validation.FieldInvalidValue, nameField, def.Name)})
+		}
+		names[def.Name] = true
+
+		typeField := fmt.Sprintf("attributes[%d].type", inc)
+		switch def.Type {
+		case model.SectorAttributeString, model.SectorAttributeNumber:
+		case model.SectorAttributeBoolean:
+			if len(def.AllowedValues) > 0 {
+				valuesField := fmt.Sprintf("attributes[%d].allowedValues", inc)
+				errorsList = append(errorsList, validation.ErrorValidation{Field: valuesField, Error: fmt.Sprintf(validation.FieldInvalidValue, valuesField, strings.Join(def.AllowedValues, ","))})
+			}
+		default:
+			errorsList = append(errorsList, validation.ErrorValidation{Field: typeField, Error: fmt.Sprintf(validation.FieldInvalidValue, typeField, string(def.Type))})
+		}
+		if def.Type == model.SectorAttributeNumber {
+			for _, allowed := range def.AllowedValues {
+				if _, errNumber := strconv.ParseFloat(allowed, 64); errNumber != nil {
+					valuesField := fmt.Sprintf("attributes[%d].allowedValues", inc)
+					errorsList = append(errorsList, validation.ErrorValidation{Field: valuesField, Error: fmt.Sprintf(validation.FieldInvalidValue, valuesField, allowed)})
+				}
+			}
+		}
+	}
+	return errorsList
+}
+
+// ValidateSectorAttributes checks the attribute values of a sector against the schema of its organization:
+// unknown attributes, missing required ones, type mismatches and values outside the allowed ones are reported
+func ValidateSectorAttributes(defs []model.SectorAttributeDef, values map[string]any) []validation.ErrorValidation {
+	var errorsList []validation.ErrorValidation
+	known := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		known[def.Name] = true
+		field := "attributes." + def.Name
+		value, present := values[def.Name]
+		if !present || value == nil {
+			if def.Required {
+				errorsList = append(errorsList, validation.ErrorValidation{Field: field, Error: fmt.Sprintf(validation.FieldRequired, field)})
+			}
+			continue
+		}
+		if !isSectorAttributeOfType(def.Type, value) || !isSectorAttributeAllowed(def, value) {
+			errorsList = append(errorsList, validation.ErrorValidation{Field: field, Error: fmt.Sprintf(validation.FieldInvalidValue, field, fmt.Sprint(value))})
+		}
+	}
+	for name, value := range values {
+		if !known[name] {
+			field := "attributes." + name
+			errorsList = append(errorsList, validation.ErrorValidation{Field: field, Error: fmt.Sprintf(validation.FieldInvalidValue, field, fmt.Sprint(value))})
+		}
+	}
+	return errorsList
+}
+
+func isSectorAttributeOfType(kind model.SectorAttributeType, value any) bool {
+	switch value.(type) {
+	case string:
+		return kind == model.SectorAttributeString
+	case float64, float32, int, int64, int32:
+		return kind == model.SectorAttributeNumber
+	case bool:
+		return kind == model.SectorAttributeBoolean
+	}
+	return false
+}
+
+func isSectorAttributeAllowed(def model.SectorAttributeDef, value any) bool {
+	if len(def.AllowedValues) == 0 {
+		return true
+	}
+	for _, allowed := range def.AllowedValues {
+		if sectorAttributeMatches(value, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// sectorAttributeMatches compares an attribute value with its text form, numbers being compared by value
+func sectorAttributeMatches(value any, text string) bool {
+	if number, isNumber := value.(float64); isNumber {
+		expected, errNumber := strconv.ParseFloat(text, 64)
+		return errNumber == nil && number == expected
+	}
+	return fmt.Sprint(value) == text
+}
+
+// FilterSectorsByAttributes keeps the sectors whose attributes match every filter, along with their ancestors so that the tree stays connected
+func FilterSectorsByAttributes(sectors []model.Sector, filters map[string]string) []model.Sector {
+	if len(filters) == 0 {
+		return sectors
+	}
+	kept := make(map[string]bool, len(sectors))
+	for _, sector := range sectors {
+		matches := true
+		for name, expected := range filters {
+			value, present := sector.Attributes[name]
+			if !present || !sectorAttributeMatches(value, expected) {
+				matches = false
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+		// The path lists the ids of the ancestors and of the sector itself
+		for _, id := range strings.Split(strings.Trim(sector.Path, "/"), "/") {
+			kept[id] = true
+		}
+	}
+
+	filtered := make([]model.Sector, 0, len(kept))
+	for _, sector := range sectors {
+		if kept[strconv.FormatInt(sector.Id, 10)] || !sector.HasParent {
+			filtered = append(filtered, sector)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,39 @@
+package helpers
+
+import (
+	"database/sql"
+	"micro-fiber-test/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSectorAttributes(t *testing.T) {
+	defs := []model.SectorAttributeDef{
+		{Name: "costCentre", Type: model.SectorAttributeString, Required: true},
+		{Name: "region", Type: model.SectorAttributeString, AllowedValues: []string{"emea", "apac"}},
+		{Name: "headcount", Type: model.SectorAttributeNumber},
+	}
+	assert.Empty(t, ValidateSectorAttributeSchema(defs))
+	assert.Len(t, ValidateSectorAttributeSchema([]model.SectorAttributeDef{{Name: "a", Type: "date"}, {Name: "a", Type: model.SectorAttributeBoolean}}), 2)
+
+	assert.Empty(t, ValidateSectorAttributes(defs, map[string]any{"costCentre": "CC1", "region": "emea", "headcount": float64(12)}))
+	errorsList := ValidateSectorAttributes(defs, map[string]any{"region": "us", "headcount": "12", "unknown": true})
+	assert.Len(t, errorsList, 4)
+}
+
+func TestFilterSectorsByAttributes(t *testing.T) {
+	sectorsList := []model.Sector{
+		{Id: 1, Path: "/1/"},
+		{Id: 2, Path: "/1/2/", HasParent: true, ParentId: sql.NullInt64{Int64: 1, Valid: true}, Attributes: map[string]any{"region": "emea"}},
+		{Id: 3, Path: "/1/2/3/", HasParent: true, ParentId: sql.NullInt64{Int64: 2, Valid: true}, Attributes: map[string]any{"region": "apac", "headcount": float64(3)}},
+		{Id: 4, Path: "/1/4/", HasParent: true, ParentId: sql.NullInt64{Int64: 1, Valid: true}, Attributes: map[string]any{"region": "apac"}},
+	}
+	filtered := FilterSectorsByAttributes(sectorsList, map[string]string{"region": "apac", "headcount": "3.0"})
+	ids := make([]int64, len(filtered))
+	for inc, sector := range filtered {
+		ids[inc] = sector.Id
+	}
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+	assert.Len(t, FilterSectorsByAttributes(sectorsList, nil), 4)
+}
@@ -0,0 +1,79 @@
+package helpers
+
+import (
+	"fmt"
+	dtos "micro-fiber-test/pkg/dto/sectors"
+	"micro-fiber-test/pkg/model"
+	"strings"
+)
+
+// diagramNode is a sector drawn in a diagram, identified by its rank in depth first order so that ids stay valid in every syntax
+type diagramNode struct {
+	id     string
+	label  string
+	parent string
+}
+
+// flattenSectorDiagram walks the tree built by BuildSectorsHierarchy, parents first and siblings in their order
+func flattenSectorDiagram(root dtos.SectorResponse, options model.SectorDiagramOptions) []diagramNode {
+	var nodes []diagramNode
+	var walk func(sector dtos.SectorResponse, parent string, level int)
+	walk = func(sector dtos.SectorResponse, parent string, level int) {
+		node := diagramNode{id: fmt.Sprintf("n%d", len(nodes)), label: sector.Label, parent: parent}
+		if options.MemberCounts != nil {
+			node.label = fmt.Sprintf("%s\n%s", sector.Label, formatMemberCount(options.MemberCounts[sector.Id]))
+		}
+		nodes = append(nodes, node)
+		if options.MaxDepth >= 0 && level >= options.MaxDepth {
+			return
+		}
+		for _, child := range sector.Children {
+			walk(child, node.id, level+1)
+		}
+	}
+	walk(root, "", 0)
+	return nodes
+}
+
+func formatMemberCount(cnt int64) string {
+	if cnt == 1 {
+		return "1 user"
+	}
+	return fmt.Sprintf("%d users", cnt)
+}
+
+// RenderSectorsDot draws the tree as a Graphviz digraph, one box per sector and one edge from each parent to its children
+func RenderSectorsDot(root dtos.SectorResponse, options model.SectorDiagramOptions) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	var sb strings.Builder
+	sb.WriteString("digraph sectors {\n")
+	sb.WriteString("    node [shape=box];\n")
+	nodes := flattenSectorDiagram(root, options)
+	for _, node := range nodes {
+		sb.WriteString(fmt.Sprintf("    %s [label=\"%s\"];\n", node.id, escaper.Replace(node.label)))
+	}
+	for _, node := range nodes {
+		if node.parent != "" {
+			sb.WriteString(fmt.Sprintf("    %s -> %s;\n", node.parent, node.id))
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// RenderSectorsMermaid draws the tree as a top-down Mermaid flowchart, labels being quoted and their special characters written as entities
+func RenderSectorsMermaid(root dtos.SectorResponse, options model.SectorDiagramOptions) string {
+	escaper := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", "<br/>")
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	nodes := flattenSectorDiagram(root, options)
+	for _, node := range nodes {
+		sb.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", node.id, escaper.Replace(node.label)))
+	}
+	for _, node := range nodes {
+		if node.parent != "" {
+			sb.WriteString(fmt.Sprintf("    %s --> %s\n", node.parent, node.id))
+		}
+	}
+	return sb.String()
+}
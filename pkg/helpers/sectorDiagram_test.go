@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"micro-fiber-test/pkg/dto/sectors"
+	"micro-fiber-test/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func diagramTree() sectors.SectorResponse {
+	return sectors.SectorResponse{
+		Id:    1,
+		Label: "root",
+		Children: []sectors.SectorResponse{
+			{Id: 2, ParentId: 1, Depth: 1, Label: `north "hq"`, Children: []sectors.SectorResponse{
+				{Id: 4, ParentId: 2, Depth: 2, Label: "north-east"},
+			}},
+			{Id: 3, ParentId: 1, Depth: 1, Label: "south"},
+		},
+	}
+}
+
+func TestSectorDiagramDot(t *testing.T) {
+	dot := RenderSectorsDot(diagramTree(), model.SectorDiagramOptions{MaxDepth: -1})
+	assert.Equal(t, "digraph sectors {\n"+
+		"    node [shape=box];\n"+
+		"    n0 [label=\"root\"];\n"+
+		"    n1 [label=\"north \\\"hq\\\"\"];\n"+
+		"    n2 [label=\"north-east\"];\n"+
+		"    n3 [label=\"south\"];\n"+
+		"    n0 -> n1;\n"+
+		"    n1 -> n2;\n"+
+		"    n0 -> n3;\n"+
+		"}\n", dot)
+}
+
+func TestSectorDiagramMermaid(t *testing.T) {
+	mermaid := RenderSectorsMermaid(diagramTree(), model.SectorDiagramOptions{MaxDepth: 1, MemberCounts: map[int64]int64{2: 1, 3: 4}})
+	assert.Equal(t, "flowchart TD\n"+
+		"    n0[\"root<br/>0 users\"]\n"+
+		"    n1[\"north #quot;hq#quot;<br/>1 user\"]\n"+
+		"    n2[\"south<br/>4 users\"]\n"+
+		"    n0 --> n1\n"+
+		"    n0 --> n2\n", mermaid)
+}
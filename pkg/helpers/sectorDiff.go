@@ -0,0 +1,124 @@
+package helpers
+
+import (
+	dtos "micro-fiber-test/pkg/dto/sectors"
+	"micro-fiber-test/pkg/model"
+)
+
+// diffNode is a sector of a compared tree, parent being the index of its parent node or -1 for the root
+type diffNode struct {
+	code   string
+	label  string
+	parent int
+}
+
+// flattenDiffTree lists the nodes of the tree breadth first, so that every parent comes before its children
+func flattenDiffTree(root dtos.SectorResponse) []diffNode {
+	nodes := []diffNode{{code: root.Code, label: root.Label, parent: -1}}
+	queue := []dtos.SectorResponse{root}
+	for next := 0; next < len(queue); next++ {
+		for _, child := range queue[next].Children {
+			nodes = append(nodes, diffNode{code: child.Code, label: child.Label, parent: next})
+			queue = append(queue, child)
+		}
+	}
+	return nodes
+}
+
+// DiffSectorTrees compares two trees as built by BuildSectorsHierarchy. Sectors are matched by code, then by label among
+// the children of matched parents, then by a label found only once among the sectors left on each side; the roots always match.
+func DiffSectorTrees(base dtos.SectorResponse, target dtos.SectorResponse) model.SectorTreeDiff {
+	baseNodes := flattenDiffTree(base)
+	targetNodes := flattenDiffTree(target)
+	// matches gives the index of the base node matched by each target node, -1 when unmatched
+	matches := make([]int, len(targetNodes))
+	matched := make([]bool, len(baseNodes))
+	for inc := range matches {
+		matches[inc] = -1
+	}
+	pair := func(targetIdx int, baseIdx int) {
+		matches[targetIdx] = baseIdx
+		matched[baseIdx] = true
+	}
+
+	baseByCode := make(map[string]int, len(baseNodes))
+	for inc, node := range baseNodes {
+		if node.code != "" {
+			baseByCode[node.code] = inc
+		}
+	}
+	for inc, node := range targetNodes {
+		if baseIdx, found := baseByCode[node.code]; found && node.code != "" {
+			pair(inc, baseIdx)
+		}
+	}
+	if matches[0] == -1 && !matched[0] {
+		pair(0, 0)
+	}
+
+	// Label among the children of matched parents, parents being matched first thanks to the breadth first order
+	for inc, node := range targetNodes {
+		if matches[inc] != -1 || node.parent == -1 || matches[node.parent] == -1 {
+			continue
+		}
+		for baseIdx, baseNode := range baseNodes {
+			if !matched[baseIdx] && baseNode.parent == matches[node.parent] && baseNode.label == node.label {
+				pair(inc, baseIdx)
+				break
+			}
+		}
+	}
+
+	// Label left once on each side, the sector moved under another parent
+	baseLabels := make(map[string][]int)
+	for inc, node := range baseNodes {
+		if !matched[inc] {
+			baseLabels[node.label] = append(baseLabels[node.label], inc)
+		}
+	}
+	targetLabels := make(map[string][]int)
+	for inc, node := range targetNodes {
+		if matches[inc] == -1 {
+			targetLabels[node.label] = append(targetLabels[node.label], inc)
+		}
+	}
+	for label, targetIdxs := range targetLabels {
+		if len(targetIdxs) == 1 && len(baseLabels[label]) == 1 {
+			pair(targetIdxs[0], baseLabels[label][0])
+		}
+	}
+
+	parentCode := func(nodes []diffNode, node diffNode) string {
+		if node.parent == -1 {
+			return ""
+		}
+		return nodes[node.parent].code
+	}
+	diff := model.SectorTreeDiff{}
+	for inc, node := range targetNodes {
+		baseIdx := matches[inc]
+		if baseIdx == -1 {
+			diff.Added = append(diff.Added, model.SectorDiffEntry{Code: node.code, Label: node.label, ParentCode: parentCode(targetNodes, node)})
+			continue
+		}
+		baseNode := baseNodes[baseIdx]
+		if baseNode.label != node.label {
+			diff.Renamed = append(diff.Renamed, model.SectorDiffEntry{Code: baseNode.code, TargetCode: node.code, Label: node.label, PreviousLabel: baseNode.label})
+		}
+		if node.parent != -1 && matches[node.parent] != baseNode.parent {
+			diff.Moved = append(diff.Moved, model.SectorDiffEntry{
+				Code:               baseNode.code,
+				TargetCode:         node.code,
+				Label:              node.label,
+				ParentCode:         parentCode(targetNodes, node),
+				PreviousParentCode: parentCode(baseNodes, baseNode),
+			})
+		}
+	}
+	for inc, node := range baseNodes {
+		if !matched[inc] {
+			diff.Removed = append(diff.Removed, model.SectorDiffEntry{Code: node.code, Label: node.label, ParentCode: parentCode(baseNodes, node)})
+		}
+	}
+	return diff
+}
@@ -0,0 +1,64 @@
+package helpers
+
+import (
+	"micro-fiber-test/pkg/dto/sectors"
+	"micro-fiber-test/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSectorDiffByCode(t *testing.T) {
+	base := sectors.SectorResponse{Code: "root", Label: "root", Children: []sectors.SectorResponse{
+		{Code: "north", Label: "north", Children: []sectors.SectorResponse{
+			{Code: "north-east", Label: "north-east"},
+		}},
+		{Code: "south", Label: "south"},
+		{Code: "west", Label: "west"},
+	}}
+	target := sectors.SectorResponse{Code: "root", Label: "root", Children: []sectors.SectorResponse{
+		{Code: "north", Label: "North", Children: []sectors.SectorResponse{
+			{Code: "south", Label: "south"},
+		}},
+		{Code: "north-east", Label: "east"},
+		{Label: "center"},
+	}}
+	diff := DiffSectorTrees(base, target)
+	assert.Equal(t, []model.SectorDiffEntry{{Label: "center", ParentCode: "root"}}, diff.Added)
+	assert.Equal(t, []model.SectorDiffEntry{{Code: "west", Label: "west", ParentCode: "root"}}, diff.Removed)
+	assert.Equal(t, []model.SectorDiffEntry{
+		{Code: "north", TargetCode: "north", Label: "North", PreviousLabel: "north"},
+		{Code: "north-east", TargetCode: "north-east", Label: "east", PreviousLabel: "north-east"},
+	}, diff.Renamed)
+	assert.Equal(t, []model.SectorDiffEntry{
+		{Code: "north-east", TargetCode: "north-east", Label: "east", ParentCode: "root", PreviousParentCode: "north"},
+		{Code: "south", TargetCode: "south", Label: "south", ParentCode: "north", PreviousParentCode: "root"},
+	}, diff.Moved)
+}
+
+func TestSectorDiffByLabel(t *testing.T) {
+	base := sectors.SectorResponse{Code: "a-root", Label: "root", Children: []sectors.SectorResponse{
+		{Code: "a-north", Label: "north", Children: []sectors.SectorResponse{
+			{Code: "a-sales", Label: "sales"},
+			{Code: "a-hr", Label: "hr"},
+		}},
+		{Code: "a-south", Label: "south", Children: []sectors.SectorResponse{
+			{Code: "a-sales2", Label: "sales"},
+		}},
+	}}
+	target := sectors.SectorResponse{Code: "b-root", Label: "root", Children: []sectors.SectorResponse{
+		{Code: "b-north", Label: "north", Children: []sectors.SectorResponse{
+			{Code: "b-sales", Label: "sales"},
+		}},
+		{Code: "b-south", Label: "south", Children: []sectors.SectorResponse{
+			{Code: "b-hr", Label: "hr"},
+		}},
+	}}
+	diff := DiffSectorTrees(base, target)
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Renamed)
+	assert.Equal(t, []model.SectorDiffEntry{{Code: "a-sales2", Label: "sales", ParentCode: "a-south"}}, diff.Removed)
+	assert.Equal(t, []model.SectorDiffEntry{
+		{Code: "a-hr", TargetCode: "b-hr", Label: "hr", ParentCode: "b-south", PreviousParentCode: "a-north"},
+	}, diff.Moved)
+}
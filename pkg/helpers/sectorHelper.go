@@ -4,36 +4,120 @@ import (
 	"errors"
 	"micro-fiber-test/pkg/dto/commons"
 	dtos "micro-fiber-test/pkg/dto/sectors"
+	"micro-fiber-test/pkg/model"
+	"sort"
 )
 
+// SectorHierarchyIssue describes why a sector could not be placed in the hierarchy
+type SectorHierarchyIssue struct {
+	Code   string
+	Reason string
+}
+
+// SectorHierarchyError lists every inconsistency found while building a hierarchy
+type SectorHierarchyError struct {
+	Issues []SectorHierarchyIssue
+}
+
+func (e SectorHierarchyError) Error() string {
+	return commons.SectorHierarchyInvalid
+}
+
+// BuildSectorsHierarchy nests the sectors under the root sector, the one without parent
 func BuildSectorsHierarchy(sectors []dtos.SectorResponse) (dtos.SectorResponse, error) {
-	var rootSector dtos.SectorResponse
-	for _, sector := range sectors {
-		if sector.Depth == 0 {
-			rootSector = sector
-			break
+	return buildSectorTree(sectors, func(sector dtos.SectorResponse) bool {
+		return sector.ParentId == 0
+	}, commons.SectorRootNotFound)
+}
+
+// BuildSectorsSubtree nests the sectors under the sector identified by rootId
+func BuildSectorsSubtree(rootId int64, sectors []dtos.SectorResponse) (dtos.SectorResponse, error) {
+	return buildSectorTree(sectors, func(sector dtos.SectorResponse) bool {
+		return sector.Id == rootId
+	}, commons.SectorNotFound)
+}
+
+// buildSectorTree indexes the sectors by id in one pass, then walks the tree breadth first from the root without recursion.
+// Siblings are ordered by position, sectors sharing a position keep their input order.
+// Orphans, extra roots, depth mismatches and sectors unreachable from the root (cycles) are reported together.
+func buildSectorTree(sectors []dtos.SectorResponse, isRoot func(dtos.SectorResponse) bool, rootNotFound string) (dtos.SectorResponse, error) {
+	byId := make(map[int64]int, len(sectors))
+	for inc, sector := range sectors {
+		byId[sector.Id] = inc
+	}
+
+	var issues []SectorHierarchyIssue
+	children := make(map[int][]int, len(sectors))
+	rootIdx := -1
+	for inc, sector := range sectors {
+		if isRoot(sector) {
+			if rootIdx >= 0 {
+				issues = append(issues, SectorHierarchyIssue{Code: sector.Code, Reason: commons.SectorMultipleRoots})
+				continue
+			}
+			rootIdx = inc
+			continue
 		}
+		parentIdx, found := byId[sector.ParentId]
+		if !found {
+			issues = append(issues, SectorHierarchyIssue{Code: sector.Code, Reason: commons.SectorOrphan})
+			continue
+		}
+		children[parentIdx] = append(children[parentIdx], inc)
 	}
-	if &rootSector == nil {
-		return rootSector, errors.New(commons.SectorRootNotFound)
+	for _, siblings := range children {
+		sort.SliceStable(siblings, func(i, j int) bool {
+			return sectors[siblings[i]].Position < sectors[siblings[j]].Position
+		})
+	}
+	if rootIdx < 0 {
+		if len(issues) == 0 {
+			return dtos.SectorResponse{}, errors.New(rootNotFound)
+		}
+		return dtos.SectorResponse{}, SectorHierarchyError{Issues: issues}
 	}
-	return fetchRecursively(&rootSector, sectors), nil
-}
 
-func fetchRecursively(parentSector *dtos.SectorResponse, sectors []dtos.SectorResponse) dtos.SectorResponse {
-	var c = make([]dtos.SectorResponse, 0)
-	for _, sector := range sectors {
-		if sector.ParentId == parentSector.Id {
-			c = append(c, fetchRecursively(&sector, sectors))
+	// Breadth first order lists every parent before its children
+	order := make([]int, 0, len(sectors))
+	order = append(order, rootIdx)
+	reached := make([]bool, len(sectors))
+	reached[rootIdx] = true
+	for next := 0; next < len(order); next++ {
+		parent := sectors[order[next]]
+		for _, childIdx := range children[order[next]] {
+			if sectors[childIdx].Depth != parent.Depth+1 {
+				issues = append(issues, SectorHierarchyIssue{Code: sectors[childIdx].Code, Reason: commons.SectorDepthMismatch})
+			}
+			reached[childIdx] = true
+			order = append(order, childIdx)
+		}
+	}
+	for inc, sector := range sectors {
+		if !reached[inc] && !isRoot(sector) {
+			if _, found := byId[sector.ParentId]; found {
+				issues = append(issues, SectorHierarchyIssue{Code: sector.Code, Reason: commons.SectorUnreachable})
+			}
 		}
 	}
-	return dtos.SectorResponse{
-		Id:       parentSector.Id,
-		Code:     parentSector.Code,
-		Label:    parentSector.Label,
-		ParentId: parentSector.ParentId,
-		Depth:    parentSector.Depth,
-		Status:   parentSector.Status,
-		Children: c,
+	if len(issues) > 0 {
+		return dtos.SectorResponse{}, SectorHierarchyError{Issues: issues}
 	}
+
+	// Children are built before their parent by walking the breadth first order backwards
+	built := make([]dtos.SectorResponse, len(sectors))
+	for next := len(order) - 1; next >= 0; next-- {
+		inc := order[next]
+		node := sectors[inc]
+		node.Children = make([]dtos.SectorResponse, len(children[inc]))
+		for pos, childIdx := range children[inc] {
+			node.Children[pos] = built[childIdx]
+		}
+		built[inc] = node
+	}
+	return built[rootIdx], nil
+}
+
+// IsSectorStatusValid tells if the status can be set on or used to filter live sectors
+func IsSectorStatusValid(status model.SectorStatus) bool {
+	return status == model.SectorStatusDraft || status == model.SectorStatusActive || status == model.SectorStatusInactive
 }
@@ -1,8 +1,10 @@
 package helpers
 
 import (
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"micro-fiber-test/pkg/dto/commons"
 	"micro-fiber-test/pkg/dto/sectors"
 	"micro-fiber-test/pkg/model"
 	"testing"
@@ -31,7 +33,7 @@ func TestSectorHierarchy(t *testing.T) {
 		Id:       3,
 		ParentId: 2,
 		Status:   model.SectorStatusActive,
-		Depth:    1,
+		Depth:    2,
 		Code:     "north-east",
 		Label:    "north-east",
 	}
@@ -40,7 +42,7 @@ func TestSectorHierarchy(t *testing.T) {
 		Id:       4,
 		ParentId: 2,
 		Status:   model.SectorStatusActive,
-		Depth:    1,
+		Depth:    2,
 		Code:     "north-west",
 		Label:    "north-west",
 	}
@@ -59,7 +61,7 @@ func TestSectorHierarchy(t *testing.T) {
 		Id:       6,
 		ParentId: 5,
 		Status:   model.SectorStatusActive,
-		Depth:    1,
+		Depth:    2,
 		Code:     "south-east",
 		Label:    "south-east",
 	}
@@ -68,17 +70,78 @@ func TestSectorHierarchy(t *testing.T) {
 		Id:       7,
 		ParentId: 5,
 		Status:   model.SectorStatusActive,
-		Depth:    1,
+		Depth:    2,
 		Code:     "south-west",
 		Label:    "south-west",
 	}
 	secList = append(secList, southWest)
 	sectorHierarchy, err := BuildSectorsHierarchy(secList)
-	if err != nil {
-		fmt.Printf("Sector error [%v]", err)
-	} else {
-		fmt.Printf("Sector hierarchy [%v]", sectorHierarchy)
-		assert.Truef(t, sectorHierarchy.Label == "root", "First sector [%s]", sectorHierarchy.Label)
-		assert.Truef(t, len(sectorHierarchy.Children) == 2, "[%d] children", 2)
+	assert.Nil(t, err)
+	fmt.Printf("Sector hierarchy [%v]", sectorHierarchy)
+	assert.Truef(t, sectorHierarchy.Label == "root", "First sector [%s]", sectorHierarchy.Label)
+	assert.Truef(t, len(sectorHierarchy.Children) == 2, "[%d] children", 2)
+	assert.Len(t, sectorHierarchy.Children[0].Children, 2)
+
+	subtree, err := BuildSectorsSubtree(south.Id, secList[4:])
+	assert.Nil(t, err)
+	assert.Equal(t, "south", subtree.Code)
+	assert.Len(t, subtree.Children, 2)
+}
+
+func TestSectorHierarchyErrors(t *testing.T) {
+	_, err := BuildSectorsHierarchy(nil)
+	assert.Equal(t, commons.SectorRootNotFound, err.Error())
+
+	secList := []sectors.SectorResponse{
+		{Id: 1, Code: "root", Depth: 0},
+		{Id: 2, Code: "other-root", Depth: 0},
+		{Id: 3, Code: "orphan", ParentId: 99, Depth: 1},
+		{Id: 4, Code: "too-deep", ParentId: 1, Depth: 3},
+		{Id: 5, Code: "loop-a", ParentId: 6, Depth: 1},
+		{Id: 6, Code: "loop-b", ParentId: 5, Depth: 1},
 	}
+	_, err = BuildSectorsHierarchy(secList)
+	var hierarchyErr SectorHierarchyError
+	assert.True(t, errors.As(err, &hierarchyErr))
+	assert.Equal(t, commons.SectorHierarchyInvalid, err.Error())
+	assert.ElementsMatch(t, []SectorHierarchyIssue{
+		{Code: "other-root", Reason: commons.SectorMultipleRoots},
+		{Code: "orphan", Reason: commons.SectorOrphan},
+		{Code: "too-deep", Reason: commons.SectorDepthMismatch},
+		{Code: "loop-a", Reason: commons.SectorUnreachable},
+		{Code: "loop-b", Reason: commons.SectorUnreachable},
+	}, hierarchyErr.Issues)
+}
+
+func TestSectorHierarchyDeepChain(t *testing.T) {
+	nbSectors := 50000
+	secList := make([]sectors.SectorResponse, nbSectors)
+	for inc := range secList {
+		secList[inc] = sectors.SectorResponse{Id: int64(inc + 1), ParentId: int64(inc), Depth: inc}
+	}
+	root, err := BuildSectorsHierarchy(secList)
+	assert.Nil(t, err)
+	depth := 0
+	for len(root.Children) > 0 {
+		root = root.Children[0]
+		depth++
+	}
+	assert.Equal(t, nbSectors-1, depth)
+}
+
+func TestSectorHierarchyPositions(t *testing.T) {
+	secList := []sectors.SectorResponse{
+		{Id: 1, Code: "root", Depth: 0},
+		{Id: 2, Code: "alpha", ParentId: 1, Depth: 1, Position: 2},
+		{Id: 3, Code: "beta", ParentId: 1, Depth: 1, Position: 0},
+		{Id: 4, Code: "gamma", ParentId: 1, Depth: 1, Position: 1},
+	}
+	root, err := BuildSectorsHierarchy(secList)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"beta", "gamma", "alpha"}, []string{root.Children[0].Code, root.Children[1].Code, root.Children[2].Code})
+}
+
+func TestSectorStatusValid(t *testing.T) {
+	assert.True(t, IsSectorStatusValid(model.SectorStatusInactive))
+	assert.False(t, IsSectorStatusValid(model.SectorStatusDeleted))
 }
@@ -0,0 +1,161 @@
+package helpers
+
+import (
+	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/model"
+)
+
+// SectorImportError lists every row of an import file that cannot be applied
+type SectorImportError struct {
+	Issues []model.SectorImportIssue
+}
+
+func (e SectorImportError) Error() string {
+	return commons.SectorImportInvalid
+}
+
+// importNode is a sector of the tree as it will be once the import is applied, line is 0 for sectors absent from the file
+type importNode struct {
+	parentCode string
+	label      string
+	line       int
+}
+
+// PlanSectorImport validates the rows against the live sectors of the organization, rows being upserted by code.
+// Nothing is planned when an issue is found: unknown parents, cycles, duplicate codes or sibling labels, invalid labels or
+// statuses, and any change of the root sector are reported with the line of the row.
+func PlanSectorImport(rows []model.SectorImportRow, existing []model.Sector) ([]model.SectorImportAction, []model.SectorImportIssue) {
+	codesById := make(map[int64]string, len(existing))
+	for _, sector := range existing {
+		codesById[sector.Id] = sector.Code
+	}
+	nodes := make(map[string]importNode, len(existing)+len(rows))
+	existingParents := make(map[string]string, len(existing))
+	rootCode := ""
+	for _, sector := range existing {
+		parentCode := ""
+		if sector.HasParent {
+			parentCode = codesById[sector.ParentId.Int64]
+		} else {
+			rootCode = sector.Code
+		}
+		existingParents[sector.Code] = parentCode
+		nodes[sector.Code] = importNode{parentCode: parentCode, label: sector.Label}
+	}
+
+	var issues []model.SectorImportIssue
+	addIssue := func(row model.SectorImportRow, reason string) {
+		issues = append(issues, model.SectorImportIssue{Line: row.Line, Code: row.Code, Reason: reason})
+	}
+	fileRows := make(map[string]model.SectorImportRow, len(rows))
+	for _, row := range rows {
+		if row.Code == "" || len(row.Code) > 50 {
+			addIssue(row, commons.SectorImportNoCode)
+			continue
+		}
+		if _, duplicate := fileRows[row.Code]; duplicate {
+			addIssue(row, commons.SectorImportDupCode)
+			continue
+		}
+		fileRows[row.Code] = row
+		if row.Label == "" || len(row.Label) > 50 {
+			addIssue(row, commons.SectorImportBadLabel)
+		}
+		if !IsSectorStatusValid(row.Status) {
+			addIssue(row, commons.SectorImportBadStatus)
+		}
+		if (row.Code == rootCode) != (row.ParentCode == "") {
+			addIssue(row, commons.SectorImportRootChange)
+		}
+		nodes[row.Code] = importNode{parentCode: row.ParentCode, label: row.Label, line: row.Line}
+	}
+	for _, row := range rows {
+		if fileRows[row.Code].Line != row.Line || row.ParentCode == "" {
+			continue
+		}
+		if _, found := nodes[row.ParentCode]; !found {
+			addIssue(row, commons.SectorImportNoParent)
+		}
+	}
+
+	// Walk up from every node, a walk coming back to one of its own nodes found a cycle
+	done := make(map[string]bool, len(nodes))
+	walkOf := make(map[string]int, len(nodes))
+	walk := 0
+	for _, row := range rows {
+		walk++
+		var path []string
+		current := row.Code
+		for current != "" && !done[current] && walkOf[current] != walk {
+			if _, found := nodes[current]; !found {
+				break
+			}
+			walkOf[current] = walk
+			path = append(path, current)
+			current = nodes[current].parentCode
+		}
+		if current != "" && !done[current] && walkOf[current] == walk {
+			inCycle := false
+			for _, code := range path {
+				inCycle = inCycle || code == current
+				if inCycle && nodes[code].line > 0 {
+					addIssue(fileRows[code], commons.SectorImportCycle)
+				}
+			}
+		}
+		for _, code := range path {
+			done[code] = true
+		}
+	}
+
+	siblings := make(map[string][]string, len(nodes))
+	for code, node := range nodes {
+		key := node.parentCode + "\x00" + node.label
+		siblings[key] = append(siblings[key], code)
+	}
+	for _, row := range rows {
+		if fileRows[row.Code].Line != row.Line {
+			continue
+		}
+		if len(siblings[row.ParentCode+"\x00"+row.Label]) > 1 {
+			addIssue(row, commons.SectorImportDupLabel)
+		}
+	}
+	if len(issues) > 0 {
+		return nil, issues
+	}
+
+	// Breadth first from the root so that every parent is written before its children
+	children := make(map[string][]string, len(nodes))
+	for _, row := range rows {
+		children[row.ParentCode] = append(children[row.ParentCode], row.Code)
+	}
+	for code, node := range nodes {
+		if _, inFile := fileRows[code]; !inFile && code != rootCode {
+			children[node.parentCode] = append(children[node.parentCode], code)
+		}
+	}
+	var actions []model.SectorImportAction
+	depths := map[string]int{rootCode: 0}
+	queue := []string{rootCode}
+	if _, inFile := fileRows[rootCode]; inFile {
+		actions = append(actions, model.SectorImportAction{Row: fileRows[rootCode], Depth: 0, Exists: true})
+	}
+	for next := 0; next < len(queue); next++ {
+		parentCode := queue[next]
+		for _, code := range children[parentCode] {
+			depths[code] = depths[parentCode] + 1
+			queue = append(queue, code)
+			if row, inFile := fileRows[code]; inFile {
+				previousParent, exists := existingParents[code]
+				actions = append(actions, model.SectorImportAction{
+					Row:    row,
+					Depth:  depths[code],
+					Exists: exists,
+					Moved:  exists && previousParent != row.ParentCode,
+				})
+			}
+		}
+	}
+	return actions, nil
+}
@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"database/sql"
+	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSectorImportPlan(t *testing.T) {
+	existing := []model.Sector{
+		{Id: 1, Code: "root", Label: "root"},
+		{Id: 2, Code: "north", Label: "north", HasParent: true, ParentId: sql.NullInt64{Int64: 1, Valid: true}, Depth: 1},
+		{Id: 3, Code: "south", Label: "south", HasParent: true, ParentId: sql.NullInt64{Int64: 1, Valid: true}, Depth: 1},
+	}
+	rows := []model.SectorImportRow{
+		{Line: 2, Code: "north-east", Label: "north-east", ParentCode: "north", Status: model.SectorStatusActive},
+		{Line: 3, Code: "south", Label: "south", ParentCode: "north", Status: model.SectorStatusInactive},
+		{Line: 4, Code: "north", Label: "North", ParentCode: "root", Status: model.SectorStatusActive},
+	}
+	actions, issues := PlanSectorImport(rows, existing)
+	assert.Empty(t, issues)
+	assert.Len(t, actions, 3)
+	assert.Equal(t, "north", actions[0].Row.Code)
+	assert.True(t, actions[0].Exists)
+	assert.False(t, actions[0].Moved)
+	assert.Equal(t, 2, actions[1].Depth)
+	assert.Equal(t, "south", actions[2].Row.Code)
+	assert.True(t, actions[2].Moved)
+}
+
+func TestSectorImportIssues(t *testing.T) {
+	existing := []model.Sector{
+		{Id: 1, Code: "root", Label: "root"},
+		{Id: 2, Code: "north", Label: "north", HasParent: true, ParentId: sql.NullInt64{Int64: 1, Valid: true}, Depth: 1},
+	}
+	rows := []model.SectorImportRow{
+		{Line: 2, Code: "a", Label: "a", ParentCode: "b"},
+		{Line: 3, Code: "b", Label: "b", ParentCode: "a"},
+		{Line: 4, Code: "c", Label: "north", ParentCode: "root"},
+		{Line: 5, Code: "d", Label: "d", ParentCode: "unknown"},
+		{Line: 6, Code: "d", Label: "d2", ParentCode: "root"},
+		{Line: 7, Code: "e", Label: "e"},
+	}
+	actions, issues := PlanSectorImport(rows, existing)
+	assert.Nil(t, actions)
+	assert.ElementsMatch(t, []model.SectorImportIssue{
+		{Line: 2, Code: "a", Reason: commons.SectorImportCycle},
+		{Line: 3, Code: "b", Reason: commons.SectorImportCycle},
+		{Line: 4, Code: "c", Reason: commons.SectorImportDupLabel},
+		{Line: 5, Code: "d", Reason: commons.SectorImportNoParent},
+		{Line: 6, Code: "d", Reason: commons.SectorImportDupCode},
+		{Line: 7, Code: "e", Reason: commons.SectorImportRootChange},
+	}, issues)
+}
@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"errors"
+	"micro-fiber-test/pkg/dto/commons"
+	"strings"
+)
+
+// SectorPathNotFoundError names the first segment of a label path that matched no sector
+type SectorPathNotFoundError struct {
+	Segment string
+}
+
+func (e SectorPathNotFoundError) Error() string {
+	return commons.SectorPathNotFound
+}
+
+// SplitSectorLabelPath splits a slash separated label path, "\/" standing for a slash and "\\" for a backslash inside a label.
+// Leading and trailing slashes are ignored and an empty path designates the root sector.
+func SplitSectorLabelPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(path, "/")
+	if strings.HasSuffix(path, "/") && !strings.HasSuffix(path, "\\/") {
+		path = strings.TrimSuffix(path, "/")
+	}
+	if path == "" {
+		return []string{}, nil
+	}
+
+	var segments []string
+	var segment strings.Builder
+	escaped := false
+	for _, char := range path {
+		switch {
+		case escaped:
+			if char != '/' && char != '\\' {
+				return nil, errors.New(commons.SectorPathInvalid)
+			}
+			segment.WriteRune(char)
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == '/':
+			if segment.Len() == 0 {
+				return nil, errors.New(commons.SectorPathInvalid)
+			}
+			segments = append(segments, segment.String())
+			segment.Reset()
+		default:
+			segment.WriteRune(char)
+		}
+	}
+	if escaped || segment.Len() == 0 {
+		return nil, errors.New(commons.SectorPathInvalid)
+	}
+	return append(segments, segment.String()), nil
+}
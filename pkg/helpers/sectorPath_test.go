@@ -0,0 +1,27 @@
+package helpers
+
+import (
+	"micro-fiber-test/pkg/dto/commons"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitSectorLabelPath(t *testing.T) {
+	segments, err := SplitSectorLabelPath("/France/Paris/Sales/")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"France", "Paris", "Sales"}, segments)
+
+	segments, err = SplitSectorLabelPath(`R\/D/Back\\Office`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"R/D", `Back\Office`}, segments)
+
+	segments, err = SplitSectorLabelPath("")
+	assert.Nil(t, err)
+	assert.Empty(t, segments)
+
+	for _, invalid := range []string{"France//Sales", `France\`, `France\x`} {
+		_, err = SplitSectorLabelPath(invalid)
+		assert.Equal(t, commons.SectorPathInvalid, err.Error(), invalid)
+	}
+}
@@ -0,0 +1,27 @@
+package helpers
+
+import (
+	"errors"
+	"micro-fiber-test/pkg/dto/commons"
+)
+
+// SelectLoginTenant picks the tenant claimed by a session among the tenants the authenticated user belongs to.
+// The tenant requested at login must be one of them, without request the user must belong to a single tenant.
+func SelectLoginTenant(requested string, userTenants []string) (string, error) {
+	if requested != "" {
+		for _, code := range userTenants {
+			if code == requested {
+				return code, nil
+			}
+		}
+		return "", errors.New(commons.TenantMismatch)
+	}
+	switch len(userTenants) {
+	case 0:
+		return "", errors.New(commons.TenantMismatch)
+	case 1:
+		return userTenants[0], nil
+	default:
+		return "", errors.New(commons.TenantNotResolved)
+	}
+}
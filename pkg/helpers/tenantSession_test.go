@@ -0,0 +1,27 @@
+package helpers
+
+import (
+	"micro-fiber-test/pkg/dto/commons"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectLoginTenant(t *testing.T) {
+	code, err := SelectLoginTenant("", []string{"acme"})
+	assert.Nil(t, err)
+	assert.Equal(t, "acme", code)
+
+	code, err = SelectLoginTenant("globex", []string{"acme", "globex"})
+	assert.Nil(t, err)
+	assert.Equal(t, "globex", code)
+
+	_, err = SelectLoginTenant("", []string{"acme", "globex"})
+	assert.EqualError(t, err, commons.TenantNotResolved)
+
+	_, err = SelectLoginTenant("initech", []string{"acme"})
+	assert.EqualError(t, err, commons.TenantMismatch)
+
+	_, err = SelectLoginTenant("", nil)
+	assert.EqualError(t, err, commons.TenantMismatch)
+}
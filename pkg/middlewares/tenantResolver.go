@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"errors"
+	"micro-fiber-test/pkg/config"
+	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/exceptions"
+	"micro-fiber-test/pkg/model"
+	"micro-fiber-test/pkg/service/api"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+)
+
+const TenantId = "tenantId"
+const TenantHeader = "x-tenant-code"
+const TenantSessionKey = "tenant"
+
+// NewTenantResolver resolves the tenant of the request and stores its id in ctx.Locals.
+// Resolution order: "tenant" session attribute, x-tenant-code header, subdomain (when enabled).
+// A header or subdomain naming another tenant than the session is rejected, the configured tenant is only used as a fallback when multi-tenancy is disabled.
+func NewTenantResolver(configuration *config.Configuration, store *session.Store, tenantSvc api.TenantServiceInterface) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var tenant model.Tenant
+		var errTenant error
+
+		tenantCode, errResolve := resolveTenantCode(configuration, store, c)
+		if errResolve != nil {
+			_ = c.SendStatus(fiber.StatusForbidden)
+			apiErr := exceptions.ConvertToFunctionalError(errResolve, fiber.StatusForbidden)
+			return c.JSON(apiErr)
+		}
+		if tenantCode != "" {
+			tenant, errTenant = tenantSvc.FindByCode(tenantCode)
+		} else if !configuration.MultiTenant {
+			tenant, errTenant = tenantSvc.FindById(configuration.TenantId)
+		} else {
+			_ = c.SendStatus(fiber.StatusBadRequest)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(commons.TenantNotResolved), fiber.StatusBadRequest)
+			return c.JSON(apiErr)
+		}
+
+		if errTenant != nil {
+			if errTenant.Error() == commons.TenantNotFound {
+				_ = c.SendStatus(fiber.StatusNotFound)
+				apiErr := exceptions.ConvertToFunctionalError(errTenant, fiber.StatusNotFound)
+				return c.JSON(apiErr)
+			}
+			_ = c.SendStatus(fiber.StatusInternalServerError)
+			apiErr := exceptions.ConvertToInternalError(errTenant)
+			return c.JSON(apiErr)
+		}
+
+		if tenant.Status == model.TenantStatusSuspended {
+			_ = c.SendStatus(fiber.StatusForbidden)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(commons.TenantSuspended), fiber.StatusForbidden)
+			return c.JSON(apiErr)
+		}
+		if tenant.Status != model.TenantStatusActive {
+			_ = c.SendStatus(fiber.StatusForbidden)
+			apiErr := exceptions.ConvertToFunctionalError(errors.New(commons.TenantInactive), fiber.StatusForbidden)
+			return c.JSON(apiErr)
+		}
+
+		c.Locals(TenantId, tenant.Id)
+		return c.Next()
+	}
+}
+
+// GetTenantId returns the tenant id stored by the tenant resolver
+func GetTenantId(c *fiber.Ctx) int64 {
+	tenantId, _ := c.Locals(TenantId).(int64)
+	return tenantId
+}
+
+// resolveTenantCode returns the tenant claimed by the session when there is one, the header and the subdomain are only
+// trusted for requests without session claim and must otherwise name the claimed tenant
+func resolveTenantCode(configuration *config.Configuration, store *session.Store, c *fiber.Ctx) (string, error) {
+	headerCode := c.Get(TenantHeader)
+	subdomainCode := ""
+	if configuration.TenantSubdomain {
+		subdomains := c.Subdomains()
+		if len(subdomains) > 0 {
+			subdomainCode = subdomains[0]
+		}
+	}
+	if store != nil {
+		httpSession, errSession := store.Get(c)
+		if errSession == nil {
+			if tenantCode, ok := httpSession.Get(TenantSessionKey).(string); ok && tenantCode != "" {
+				if headerCode != "" && headerCode != tenantCode {
+					return "", errors.New(commons.TenantMismatch)
+				}
+				if subdomainCode != "" && subdomainCode != tenantCode {
+					return "", errors.New(commons.TenantMismatch)
+				}
+				return tenantCode, nil
+			}
+		}
+	}
+	if headerCode != "" {
+		return headerCode, nil
+	}
+	return subdomainCode, nil
+}
@@ -0,0 +1,137 @@
+package middlewares
+
+import (
+	"errors"
+	"micro-fiber-test/pkg/config"
+	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/model"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"github.com/stretchr/testify/assert"
+)
+
+// tenantSvcStub resolves tenants from an in memory list
+type tenantSvcStub struct {
+	tenants []model.Tenant
+}
+
+func (s tenantSvcStub) Create(tenant model.Tenant) (int64, error)     { return 0, nil }
+func (s tenantSvcStub) Rename(code string, label string) error        { return nil }
+func (s tenantSvcStub) Suspend(code string) error                     { return nil }
+func (s tenantSvcStub) Activate(code string) error                    { return nil }
+func (s tenantSvcStub) Delete(code string) error                      { return nil }
+func (s tenantSvcStub) FindAll() ([]model.Tenant, error)              { return s.tenants, nil }
+func (s tenantSvcStub) FindCodesByUserLogin(string) ([]string, error) { return nil, nil }
+func (s tenantSvcStub) FindById(id int64) (model.Tenant, error) {
+	for _, tenant := range s.tenants {
+		if tenant.Id == id {
+			return tenant, nil
+		}
+	}
+	return model.Tenant{}, errors.New(commons.TenantNotFound)
+}
+func (s tenantSvcStub) FindByCode(code string) (model.Tenant, error) {
+	for _, tenant := range s.tenants {
+		if tenant.Code == code {
+			return tenant, nil
+		}
+	}
+	return model.Tenant{}, errors.New(commons.TenantNotFound)
+}
+
+// newResolverApp serves the resolved tenant id on /api, /login stores the tenant claim of the query in the session
+func newResolverApp(configuration *config.Configuration) *fiber.App {
+	store := session.New()
+	tenantSvc := tenantSvcStub{tenants: []model.Tenant{
+		{Id: 1, Code: "acme", Status: model.TenantStatusActive},
+		{Id: 2, Code: "globex", Status: model.TenantStatusActive},
+	}}
+	app := fiber.New()
+	app.Get("/login", func(c *fiber.Ctx) error {
+		httpSession, err := store.Get(c)
+		if err != nil {
+			return err
+		}
+		httpSession.Set(TenantSessionKey, c.Query("tenant"))
+		return httpSession.Save()
+	})
+	app.Use("/api", NewTenantResolver(configuration, store, tenantSvc))
+	app.Get("/api", func(c *fiber.Ctx) error {
+		return c.SendString(strconv.FormatInt(GetTenantId(c), 10))
+	})
+	return app
+}
+
+func login(t *testing.T, app *fiber.App, tenant string) *http.Cookie {
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/login?tenant="+tenant, nil))
+	assert.Nil(t, err)
+	cookies := resp.Cookies()
+	assert.Len(t, cookies, 1)
+	return cookies[0]
+}
+
+func resolve(t *testing.T, app *fiber.App, host string, cookie *http.Cookie, header string) (int, string) {
+	req := httptest.NewRequest(fiber.MethodGet, "http://"+host+"/api", nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	if header != "" {
+		req.Header.Set(TenantHeader, header)
+	}
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	if resp.StatusCode != fiber.StatusOK {
+		return resp.StatusCode, ""
+	}
+	body := make([]byte, 8)
+	n, _ := resp.Body.Read(body)
+	return resp.StatusCode, string(body[:n])
+}
+
+func TestTenantResolverSessionPrecedence(t *testing.T) {
+	app := newResolverApp(&config.Configuration{MultiTenant: true})
+
+	// Without session the header is trusted
+	status, tenantId := resolve(t, app, "example.com", nil, "globex")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "2", tenantId)
+
+	// The session claim resolves the tenant without header, a matching header is accepted
+	cookie := login(t, app, "acme")
+	status, tenantId = resolve(t, app, "example.com", cookie, "")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "1", tenantId)
+	status, tenantId = resolve(t, app, "example.com", cookie, "acme")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "1", tenantId)
+
+	// A header naming another tenant cannot override the session claim
+	status, _ = resolve(t, app, "example.com", cookie, "globex")
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	// Without session nor header a multi-tenant deployment cannot resolve the tenant
+	status, _ = resolve(t, app, "example.com", nil, "")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestTenantResolverSessionSubdomain(t *testing.T) {
+	app := newResolverApp(&config.Configuration{MultiTenant: true, TenantSubdomain: true})
+
+	status, tenantId := resolve(t, app, "globex.example.com", nil, "")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "2", tenantId)
+
+	cookie := login(t, app, "acme")
+	status, tenantId = resolve(t, app, "acme.example.com", cookie, "")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "1", tenantId)
+
+	// A subdomain naming another tenant cannot override the session claim
+	status, _ = resolve(t, app, "globex.example.com", cookie, "")
+	assert.Equal(t, fiber.StatusForbidden, status)
+}
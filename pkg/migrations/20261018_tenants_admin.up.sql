@@ -0,0 +1,4 @@
+create sequence tenants_id_seq as bigint increment by 1 minvalue 1 start with 1;
+select setval('tenants_id_seq', (select coalesce(max(id), 1) from tenants));
+alter table tenants alter column id set default nextval('tenants_id_seq');
+alter table tenants add constraint tenants_code_uk unique (code);
@@ -0,0 +1,7 @@
+alter table organizations add column deleted_at timestamp with time zone;
+alter table sectors add column deleted_at timestamp with time zone;
+alter table users add column deleted_at timestamp with time zone;
+
+create index organizations_deleted_at_idx on organizations(deleted_at) where deleted_at is not null;
+create index sectors_deleted_at_idx on sectors(deleted_at) where deleted_at is not null;
+create index users_deleted_at_idx on users(deleted_at) where deleted_at is not null;
@@ -0,0 +1,19 @@
+create table users_sectors(
+	tenant_id bigint not null references tenants(id),
+	user_id bigint not null references users(id) on delete cascade,
+	sector_id bigint not null references sectors(id) on delete cascade,
+	primary key (user_id, sector_id)
+);
+create index users_sectors_sector_idx on users_sectors(sector_id);
+
+create sequence sector_templates_id_seq as bigint increment by 1 minvalue 1 start with 1;
+
+create table sector_templates(
+	id bigint primary key default nextval('sector_templates_id_seq'),
+	tenant_id bigint not null references tenants(id),
+	code varchar(50) not null,
+	label varchar(100) not null,
+	nodes jsonb not null default '[]',
+	constraint sector_templates_code_uk unique (tenant_id, code),
+	constraint sector_templates_label_uk unique (tenant_id, label)
+);
@@ -0,0 +1,2 @@
+alter table organizations add column parent_id bigint references organizations(id);
+create index organizations_parent_idx on organizations(parent_id);
@@ -0,0 +1,3 @@
+alter table organizations add column version integer not null default 1;
+alter table sectors add column version integer not null default 1;
+alter table users add column version integer not null default 1;
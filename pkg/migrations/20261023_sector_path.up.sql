@@ -0,0 +1,4 @@
+alter table sectors add column path text;
+with recursive tree(id,path) as (select id,'/'||id||'/' from sectors where parent_id is null union all select s.id,t.path||s.id||'/' from sectors s join tree t on s.parent_id=t.id) update sectors u set path=tree.path from tree where u.id=tree.id;
+alter table sectors alter column path set not null;
+create index sectors_path_idx on sectors(path text_pattern_ops);
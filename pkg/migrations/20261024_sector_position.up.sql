@@ -0,0 +1,3 @@
+alter table sectors add column position integer not null default 0;
+update sectors u set position=o.pos from (select id,row_number() over (partition by parent_id order by label asc)-1 as pos from sectors where deleted_at is null) o where u.id=o.id;
+create index sectors_parent_position_idx on sectors(parent_id,position);
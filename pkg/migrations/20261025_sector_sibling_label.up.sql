@@ -0,0 +1,3 @@
+-- Duplicate sibling labels must be renamed by an operator, the migration stops and lists them rather than rewriting labels
+do $$ declare conflicts text; begin select string_agg(format('org %s, parent %s, label %L: sectors %s', d.org_id, coalesce(d.parent_id::text, 'none'), d.label, d.ids), '; ') into conflicts from (select org_id,parent_id,label,string_agg(id::text,',' order by id) as ids from sectors where deleted_at is null group by org_id,parent_id,label having count(1)>1) d; if conflicts is not null then raise exception 'duplicate sibling sector labels, rename them before migrating: %', conflicts; end if; end $$;
+create unique index sectors_sibling_label_uk on sectors(org_id,coalesce(parent_id,0),label) where deleted_at is null;
@@ -0,0 +1,8 @@
+alter table sectors add column attributes jsonb not null default '{}';
+create index sectors_attributes_idx on sectors using gin(attributes);
+
+create table sector_attribute_schemas(
+	org_id bigint primary key references organizations(id) on delete cascade,
+	tenant_id bigint not null references tenants(id),
+	attributes jsonb not null default '[]'
+);
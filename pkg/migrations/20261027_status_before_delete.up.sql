@@ -0,0 +1,4 @@
+alter table sectors add column status_before_delete smallint;
+alter table users add column status_before_delete smallint;
+update sectors set status_before_delete=1 where deleted_at is not null;
+update users set status_before_delete=1 where deleted_at is not null;
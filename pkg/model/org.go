@@ -1,10 +1,16 @@
 package model
 
+import "database/sql"
+
 type Organization struct {
-	Id       int64              `db:"id"`
-	TenantId int64              `db:"tenant_id"`
-	Code     string             `db:"code"`
-	Label    string             `db:"label"`
-	Type     OrganizationType   `db:"type"`
-	Status   OrganizationStatus `db:"status"`
+	Id         int64              `db:"id"`
+	TenantId   int64              `db:"tenant_id"`
+	Code       string             `db:"code"`
+	Label      string             `db:"label"`
+	Type       OrganizationType   `db:"type"`
+	Status     OrganizationStatus `db:"status"`
+	ParentId   sql.NullInt64      `db:"parent_id"`
+	ParentCode sql.NullString     `db:"parent_code"`
+	Version    int64              `db:"version"`
+	DeletedAt  sql.NullTime       `db:"deleted_at"`
 }
@@ -0,0 +1,6 @@
+package model
+
+type OrgCloneOptions struct {
+	Label       string
+	CopyMembers bool
+}
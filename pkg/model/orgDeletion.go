@@ -0,0 +1,23 @@
+package model
+
+type OrgDeleteStrategy string
+
+const (
+	OrgDeleteRefuse  OrgDeleteStrategy = "refuse"
+	OrgDeleteCascade OrgDeleteStrategy = "cascade"
+	OrgDeleteMove    OrgDeleteStrategy = "move"
+)
+
+type OrgDeleteOptions struct {
+	Strategy      OrgDeleteStrategy
+	TargetOrgCode string
+	DryRun        bool
+	Version       int64
+}
+
+type OrgDeletionReport struct {
+	Users    int64
+	Sectors  int64
+	Children int64
+	Refused  bool
+}
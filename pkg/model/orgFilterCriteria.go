@@ -0,0 +1,20 @@
+package model
+
+type OrgSortField string
+
+const (
+	OrgSortByLabel OrgSortField = "label"
+	OrgSortByCode  OrgSortField = "code"
+)
+
+type OrgFilterCriteria struct {
+	TenantId    int64
+	Type        OrganizationType
+	Status      *OrganizationStatus
+	Label       string
+	ParentId    *int64
+	SortBy      OrgSortField
+	SortDesc    bool
+	RowsPerPage int
+	Page        int
+}
@@ -4,11 +4,14 @@ type OrganizationStatus int64
 
 type OrganizationType string
 
+type OrgLifecycleAction string
+
 const (
 	OrgStatusDraft    OrganizationStatus = 0
 	OrgStatusActive   OrganizationStatus = 1
 	OrgStatusInactive OrganizationStatus = 2
 	OrgStatusDeleted  OrganizationStatus = 3
+	OrgStatusArchived OrganizationStatus = 4
 )
 
 const (
@@ -17,3 +20,10 @@ const (
 	OrgTypeCommunity  OrganizationType = "community"
 	OrgTypeEnterprise OrganizationType = "enterprise"
 )
+
+const (
+	OrgActionActivate   OrgLifecycleAction = "activate"
+	OrgActionDeactivate OrgLifecycleAction = "deactivate"
+	OrgActionArchive    OrgLifecycleAction = "archive"
+	OrgActionRestore    OrgLifecycleAction = "restore"
+)
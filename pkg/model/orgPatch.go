@@ -0,0 +1,10 @@
+package model
+
+// OrganizationPatch holds the attributes of a merge patch, nil attributes are left untouched.
+// An empty ParentCode detaches the organization from its parent.
+type OrganizationPatch struct {
+	Label      *string
+	Type       *OrganizationType
+	Status     *OrganizationStatus
+	ParentCode *string
+}
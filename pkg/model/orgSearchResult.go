@@ -0,0 +1,6 @@
+package model
+
+type OrgSearchResult struct {
+	NbResults     int
+	Organizations []Organization
+}
@@ -0,0 +1,7 @@
+package model
+
+type PurgeReport struct {
+	Users         int64
+	Sectors       int64
+	Organizations int64
+}
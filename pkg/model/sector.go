@@ -2,14 +2,22 @@ package model
 
 import "database/sql"
 
+// SectorPositionLast places a new sector after its existing siblings
+const SectorPositionLast = -1
+
 type Sector struct {
-	Id        int64         `db:"id"`
-	TenantId  int64         `db:"tenant_id"`
-	OrgId     int64         `db:"org_id"`
-	Code      string        `db:"code"`
-	Label     string        `db:"label"`
-	ParentId  sql.NullInt64 `db:"parent_id"`
-	HasParent bool          `db:"has_parent"`
-	Depth     int           `db:"depth"`
-	Status    SectorStatus  `db:"status"`
+	Id         int64          `db:"id"`
+	TenantId   int64          `db:"tenant_id"`
+	OrgId      int64          `db:"org_id"`
+	Code       string         `db:"code"`
+	Label      string         `db:"label"`
+	ParentId   sql.NullInt64  `db:"parent_id"`
+	HasParent  bool           `db:"has_parent"`
+	Depth      int            `db:"depth"`
+	Path       string         `db:"path"`
+	Position   int            `db:"position"`
+	Status     SectorStatus   `db:"status"`
+	Attributes map[string]any `db:"attributes"`
+	Version    int64          `db:"version"`
+	DeletedAt  sql.NullTime   `db:"deleted_at"`
 }
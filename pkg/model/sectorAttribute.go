@@ -0,0 +1,23 @@
+package model
+
+type SectorAttributeType string
+
+const (
+	SectorAttributeString  SectorAttributeType = "string"
+	SectorAttributeNumber  SectorAttributeType = "number"
+	SectorAttributeBoolean SectorAttributeType = "boolean"
+)
+
+// SectorAttributeDef describes a custom attribute of the sectors of an organization, stored as JSON
+type SectorAttributeDef struct {
+	Name          string              `json:"name"`
+	Type          SectorAttributeType `json:"type"`
+	Required      bool                `json:"required"`
+	AllowedValues []string            `json:"allowedValues,omitempty"`
+}
+
+type SectorAttributeSchema struct {
+	OrgId      int64                `db:"org_id"`
+	TenantId   int64                `db:"tenant_id"`
+	Attributes []SectorAttributeDef `db:"attributes"`
+}
@@ -0,0 +1,19 @@
+package model
+
+type SectorDeleteMode string
+
+const (
+	SectorDeleteCascade  SectorDeleteMode = "cascade"
+	SectorDeleteReparent SectorDeleteMode = "reparent"
+)
+
+type SectorDeleteOptions struct {
+	Mode    SectorDeleteMode
+	Version int64
+}
+
+type SectorDeletionReport struct {
+	Sectors     int64
+	Reparented  int64
+	Assignments int64
+}
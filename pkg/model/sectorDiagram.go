@@ -0,0 +1,15 @@
+package model
+
+type SectorDiagramFormat string
+
+const (
+	SectorDiagramDot     SectorDiagramFormat = "dot"
+	SectorDiagramMermaid SectorDiagramFormat = "mermaid"
+)
+
+// SectorDiagramOptions drives the rendering of a sectors tree, a negative MaxDepth draws the whole tree and
+// member counts are only shown when MemberCounts is set, sectors absent from it having no member
+type SectorDiagramOptions struct {
+	MaxDepth     int
+	MemberCounts map[int64]int64
+}
@@ -0,0 +1,21 @@
+package model
+
+// SectorDiffEntry is a sector that differs between the base tree and the target tree. Code and ParentCode are taken from
+// the base tree for removed, renamed and moved sectors and from the target tree for added ones, TargetCode being the code
+// of a matched sector in the target tree
+type SectorDiffEntry struct {
+	Code               string
+	TargetCode         string
+	Label              string
+	PreviousLabel      string
+	ParentCode         string
+	PreviousParentCode string
+}
+
+// SectorTreeDiff lists the changes turning the base tree into the target tree, a sector both renamed and moved is listed twice
+type SectorTreeDiff struct {
+	Added   []SectorDiffEntry
+	Removed []SectorDiffEntry
+	Renamed []SectorDiffEntry
+	Moved   []SectorDiffEntry
+}
@@ -0,0 +1,39 @@
+package model
+
+type SectorExchangeFormat string
+
+const (
+	SectorFormatJson SectorExchangeFormat = "json"
+	SectorFormatCsv  SectorExchangeFormat = "csv"
+)
+
+// SectorImportRow is a sector read from an import file, Line is its line in a CSV file or its rank in a JSON tree.
+// Nil attributes keep the attributes of an existing sector.
+type SectorImportRow struct {
+	Line       int
+	Code       string
+	Label      string
+	ParentCode string
+	Status     SectorStatus
+	Attributes map[string]any
+}
+
+type SectorImportIssue struct {
+	Line   int
+	Code   string
+	Reason string
+}
+
+// SectorImportAction is a validated row with the depth it will have once imported, actions are listed parents first
+type SectorImportAction struct {
+	Row    SectorImportRow
+	Depth  int
+	Exists bool
+	Moved  bool
+}
+
+type SectorImportReport struct {
+	Created int64
+	Updated int64
+	Moved   int64
+}
@@ -0,0 +1,26 @@
+package model
+
+// SectorMergeOptions merges a sector into the sector identified by TargetCode, nothing being written in dry run mode
+type SectorMergeOptions struct {
+	TargetCode string
+	DryRun     bool
+	Version    int64
+}
+
+// SectorSplitOptions creates a sibling sector of code Code and label Label, and moves into it the listed children
+// and users (by external id) of the split sector
+type SectorSplitOptions struct {
+	Code       string
+	Label      string
+	ChildCodes []string
+	UserIds    []string
+	DryRun     bool
+	Version    int64
+}
+
+// SectorReorganisationReport is the outcome of a merge or a split, Code being the sector receiving the children and users
+type SectorReorganisationReport struct {
+	Code        string
+	Children    int64
+	Assignments int64
+}
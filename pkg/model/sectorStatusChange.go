@@ -0,0 +1,9 @@
+package model
+
+// SectorStatusOptions drives a status change: Cascade applies it to the live descendants,
+// Force deactivates even when active users are assigned and Version is the expected version of the sector (0 skips the check)
+type SectorStatusOptions struct {
+	Cascade bool
+	Force   bool
+	Version int64
+}
@@ -0,0 +1,16 @@
+package model
+
+type SectorTemplate struct {
+	Id       int64                `db:"id"`
+	TenantId int64                `db:"tenant_id"`
+	Code     string               `db:"code"`
+	Label    string               `db:"label"`
+	Nodes    []SectorTemplateNode `db:"nodes"`
+}
+
+// SectorTemplateNode is a sector of a template tree, stored as JSON
+type SectorTemplateNode struct {
+	Label      string               `json:"label"`
+	Attributes map[string]any       `json:"attributes,omitempty"`
+	Children   []SectorTemplateNode `json:"children,omitempty"`
+}
@@ -0,0 +1,10 @@
+package model
+
+// SectorTreeNode is a sector created along with its children in one operation, Code is set once created
+type SectorTreeNode struct {
+	Code       string
+	Label      string
+	Status     SectorStatus
+	Attributes map[string]any
+	Children   []SectorTreeNode
+}
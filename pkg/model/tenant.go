@@ -0,0 +1,8 @@
+package model
+
+type Tenant struct {
+	Id     int64        `db:"id"`
+	Code   string       `db:"code"`
+	Label  string       `db:"label"`
+	Status TenantStatus `db:"status"`
+}
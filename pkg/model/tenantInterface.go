@@ -0,0 +1,9 @@
+package model
+
+type TenantStatus int64
+
+const (
+	TenantStatusActive    TenantStatus = 0
+	TenantStatusInactive  TenantStatus = 1
+	TenantStatusSuspended TenantStatus = 2
+)
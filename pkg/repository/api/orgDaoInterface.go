@@ -2,17 +2,29 @@ package api
 
 import (
 	"micro-fiber-test/pkg/model"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
 
 type OrgDaoInterface interface {
 	Create(organization model.Organization) (int64, error)
-	Update(orgCode string, label string) error
-	Delete(orgCode string) error
-	FindByCode(code string) (model.Organization, error)
+	Update(tenantId int64, orgCode string, label string, version int64) (int64, error)
+	Patch(organization model.Organization, version int64) (int64, error)
+	UpdateStatus(tenantId int64, orgCode string, status model.OrganizationStatus) error
+	DeleteInTx(tx pgx.Tx, tenantId int64, orgId int64, deletedAt time.Time, version int64) (int64, error)
+	RestoreInTx(tx pgx.Tx, tenantId int64, orgId int64, status model.OrganizationStatus) error
+	PurgeInTx(tx pgx.Tx, deletedBefore time.Time) (int64, error)
+	FindByCode(tenantId int64, code string) (model.Organization, error)
+	FindDeletedByCode(tenantId int64, code string) (model.Organization, error)
 	FindAll(tenantId int64) ([]model.Organization, error)
+	FindChildren(tenantId int64, orgId int64) ([]model.Organization, error)
+	CountChildrenInTx(tx pgx.Tx, orgId int64) (int64, error)
+	FindAncestorIds(orgId int64) ([]int64, error)
+	FindByCriteria(criteria model.OrgFilterCriteria) (model.OrgSearchResult, error)
+	CountByCriteria(criteria model.OrgFilterCriteria) (int, error)
 	ExistsByCode(tenantId int64, code string) (bool, error)
 	ExistsByLabel(tenantId int64, label string) (bool, error)
+	FindIdByLabel(tenantId int64, label string) (int64, error)
 	CreateInTx(tx pgx.Tx, organization model.Organization) (int64, error)
 }
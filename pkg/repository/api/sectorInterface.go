@@ -2,18 +2,53 @@ package api
 
 import (
 	"micro-fiber-test/pkg/model"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
 
 type SectorDaoInterface interface {
 	Create(sector model.Sector) (int64, error)
-	DeleteByOrgId(orgId int64) error
-	Update(defaultTenantId int64, id int64, label string) error
+	DeleteByOrgIdInTx(tx pgx.Tx, orgId int64, deletedAt time.Time) error
+	RestoreByOrgIdInTx(tx pgx.Tx, orgId int64, deletedAt time.Time) error
+	PurgeInTx(tx pgx.Tx, deletedBefore time.Time) (int64, error)
+	CountByOrgIdInTx(tx pgx.Tx, orgId int64) (int64, error)
+	Update(defaultTenantId int64, id int64, label string, attributes map[string]any, version int64) (int64, error)
 	CreateInTx(tx pgx.Tx, sector model.Sector) (int64, error)
 	FindSectorsByTenantOrg(defaultTenantId int64, orgId int64) ([]model.Sector, error)
 	FindByCode(defaultTenantId int64, code string) (model.Sector, error)
+	FindById(defaultTenantId int64, sectorId int64) (model.Sector, error)
+	FindDeletedByCode(defaultTenantId int64, code string) (model.Sector, error)
+	ExistsById(defaultTenantId int64, sectorId int64) (bool, error)
 	FindRootSector(defaultTenantId int64, orgId int64) (int64, error)
-	DeleteSector(defaultTenantId int64, sectorId int64) error
-	FindByLabel(defaultTenantId int64, label string) (int64, string, error)
+	DeleteInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, deletedAt time.Time, version int64) (int64, error)
+	DeleteSubtreeInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, deletedAt time.Time, version int64) (int64, error)
+	ReparentChildrenInTx(tx pgx.Tx, sectorId int64, parentId int64) ([]int64, error)
+	CountMembersInTx(tx pgx.Tx, sectorId int64) (int64, error)
+	CountSubtreeMembersInTx(tx pgx.Tx, sectorId int64) (int64, error)
+	CountMembersBySector(defaultTenantId int64, orgId int64) (map[int64]int64, error)
+	RestoreSectorInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, deletedAt time.Time) error
+	FindSiblingByLabel(defaultTenantId int64, orgId int64, parentId int64, label string) (int64, string, error)
+	FindSubtree(defaultTenantId int64, sectorId int64, maxDepth int) ([]model.Sector, error)
+	FindAncestors(defaultTenantId int64, sectorId int64) ([]model.Sector, error)
+	IsDescendantOf(defaultTenantId int64, sectorId int64, ancestorId int64) (bool, error)
+	MoveInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, parentId int64, version int64) (int64, error)
+	RebaseSubtreeInTx(tx pgx.Tx, sectorId int64, parentId int64) error
+	FindSectorsByStatus(defaultTenantId int64, orgId int64, statuses []int64) ([]model.Sector, error)
+	UpdateStatusInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, status model.SectorStatus, version int64) (int64, error)
+	UpdateDescendantsStatusInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, status model.SectorStatus) (int64, error)
+	CountActiveMembersInTx(tx pgx.Tx, sectorId int64, withDescendants bool) (int64, error)
+	FindCodesInUse(defaultTenantId int64, orgId int64, codes []string) ([]string, error)
+	ImportUpdateInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, label string, status model.SectorStatus, attributes map[string]any) (int64, error)
+	CountChildrenInTx(tx pgx.Tx, parentId int64) (int, error)
+	FindChildren(defaultTenantId int64, parentId int64) ([]model.Sector, error)
+	ShiftPositionsInTx(tx pgx.Tx, parentId int64, fromPosition int) error
+	CompactPositionsInTx(tx pgx.Tx, parentId int64) error
+	ReorderChildren(defaultTenantId int64, parentId int64, codes []string) error
+	AddMember(defaultTenantId int64, sectorId int64, userId int64) error
+	RemoveMember(defaultTenantId int64, sectorId int64, userId int64) error
+	MoveMembersInTx(tx pgx.Tx, fromSectorId int64, toSectorId int64) error
+	MoveMemberInTx(tx pgx.Tx, userId int64, fromSectorId int64, toSectorId int64) (int64, error)
+	UpdateAttributesInTx(tx pgx.Tx, sectorId int64, attributes map[string]any) error
+	DeleteOrgMembershipsInTx(tx pgx.Tx, orgId int64) error
 }
@@ -0,0 +1,13 @@
+package api
+
+import (
+	"micro-fiber-test/pkg/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type SectorSchemaDaoInterface interface {
+	FindByOrgId(tenantId int64, orgId int64) (model.SectorAttributeSchema, error)
+	Save(schema model.SectorAttributeSchema) error
+	CopyInTx(tx pgx.Tx, fromOrgId int64, toOrgId int64) error
+}
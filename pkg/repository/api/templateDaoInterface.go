@@ -0,0 +1,11 @@
+package api
+
+import "micro-fiber-test/pkg/model"
+
+type TemplateDaoInterface interface {
+	Create(template model.SectorTemplate) (int64, error)
+	Delete(tenantId int64, code string) error
+	FindByCode(tenantId int64, code string) (model.SectorTemplate, error)
+	FindAll(tenantId int64) ([]model.SectorTemplate, error)
+	ExistsByLabel(tenantId int64, label string) (bool, error)
+}
@@ -0,0 +1,17 @@
+package api
+
+import "micro-fiber-test/pkg/model"
+
+type TenantDaoInterface interface {
+	Create(tenant model.Tenant) (int64, error)
+	Update(code string, label string) error
+	UpdateStatus(code string, status model.TenantStatus) error
+	Delete(code string) error
+	FindById(id int64) (model.Tenant, error)
+	FindByCode(code string) (model.Tenant, error)
+	FindAll() ([]model.Tenant, error)
+	ExistsByCode(code string) (bool, error)
+	CountOrganizations(tenantId int64) (int, error)
+	CountSectorSettings(tenantId int64) (int, error)
+	FindCodesByUserLogin(login string) ([]string, error)
+}
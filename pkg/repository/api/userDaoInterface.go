@@ -2,15 +2,26 @@ package api
 
 import (
 	"micro-fiber-test/pkg/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type UserDaoInterface interface {
 	Create(user model.User) (int64, error)
 	FindByExternalId(tenantId int64, orgId int64, externalId string) (model.User, error)
+	FindDeletedByExternalId(tenantId int64, orgId int64, externalId string) (model.User, error)
+	FindIdByExternalId(tenantId int64, externalId string) (int64, error)
 	FindByCriteria(criteria model.UserFilterCriteria) (model.UserSearchResult, error)
 	CountByCriteria(criteria model.UserFilterCriteria) (int, error)
-	Update(user model.User) error
-	IsLoginInUse(login string) (int64, string, error)
-	IsEmailInUse(email string) (int64, string, error)
-	Delete(userExtId string) error
+	Update(user model.User) (int64, error)
+	IsLoginInUse(tenantId int64, login string) (int64, string, error)
+	IsEmailInUse(tenantId int64, email string) (int64, string, error)
+	Delete(tenantId int64, userExtId string, version int64) (int64, error)
+	Restore(tenantId int64, userExtId string) error
+	PurgeInTx(tx pgx.Tx, deletedBefore time.Time) (int64, error)
+	CountByOrgIdInTx(tx pgx.Tx, orgId int64) (int64, error)
+	DeleteByOrgIdInTx(tx pgx.Tx, orgId int64, deletedAt time.Time) error
+	RestoreByOrgIdInTx(tx pgx.Tx, orgId int64, deletedAt time.Time) error
+	MoveToOrgInTx(tx pgx.Tx, fromOrgId int64, toOrgId int64) error
 }
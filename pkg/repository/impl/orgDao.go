@@ -4,6 +4,9 @@ import (
 	"context"
 	"micro-fiber-test/pkg/model"
 	"micro-fiber-test/pkg/repository/api"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -25,33 +28,79 @@ func NewOrgDao(pool *pgxpool.Pool, kSql *koanf.Koanf) api.OrgDaoInterface {
 func (orgRepo *OrgDao) CreateInTx(tx pgx.Tx, org model.Organization) (int64, error) {
 	var id int64
 	insertStmt := orgRepo.koanf.String("organizations.create")
-	errQuery := tx.QueryRow(context.Background(), insertStmt, org.TenantId, org.Code, org.Label, org.Type, org.Status).Scan(&id)
+	errQuery := tx.QueryRow(context.Background(), insertStmt, org.TenantId, org.Code, org.Label, org.Type, org.Status, org.ParentId).Scan(&id)
 	return id, errQuery
 }
 
 func (orgRepo *OrgDao) Create(org model.Organization) (int64, error) {
 	var id int64
 	insertStmt := orgRepo.koanf.String("organizations.create")
-	errQuery := orgRepo.dbPool.QueryRow(context.Background(), insertStmt, org.TenantId, org.Code, org.Label, org.Type, org.Status).Scan(&id)
+	errQuery := orgRepo.dbPool.QueryRow(context.Background(), insertStmt, org.TenantId, org.Code, org.Label, org.Type, org.Status, org.ParentId).Scan(&id)
 	return id, errQuery
 }
 
-func (orgRepo *OrgDao) Update(orgCode string, label string) error {
+// Update changes the label when the organization is still at the expected version (0 skips the check) and returns the number of updated rows
+func (orgRepo *OrgDao) Update(tenantId int64, orgCode string, label string, version int64) (int64, error) {
 	updateStmt := orgRepo.koanf.String("organizations.update")
-	_, errQuery := orgRepo.dbPool.Exec(context.Background(), updateStmt, label, orgCode)
+	cmdTag, errQuery := orgRepo.dbPool.Exec(context.Background(), updateStmt, label, tenantId, orgCode, version)
+	if errQuery != nil {
+		return 0, errQuery
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+func (orgRepo *OrgDao) Patch(org model.Organization, version int64) (int64, error) {
+	patchStmt := orgRepo.koanf.String("organizations.patch")
+	cmdTag, errQuery := orgRepo.dbPool.Exec(context.Background(), patchStmt, org.Label, org.Type, org.Status, org.ParentId, org.TenantId, org.Code, version)
+	if errQuery != nil {
+		return 0, errQuery
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+func (orgRepo *OrgDao) UpdateStatus(tenantId int64, orgCode string, status model.OrganizationStatus) error {
+	updateStmt := orgRepo.koanf.String("organizations.updatestatus")
+	_, errQuery := orgRepo.dbPool.Exec(context.Background(), updateStmt, status, tenantId, orgCode)
 	return errQuery
 }
 
-func (orgRepo *OrgDao) Delete(orgCode string) error {
-	deleteStmt := orgRepo.koanf.String("organizations.update")
-	_, errQuery := orgRepo.dbPool.Exec(context.Background(), deleteStmt, orgCode)
+func (orgRepo *OrgDao) DeleteInTx(tx pgx.Tx, tenantId int64, orgId int64, deletedAt time.Time, version int64) (int64, error) {
+	deleteStmt := orgRepo.koanf.String("organizations.delete")
+	cmdTag, errQuery := tx.Exec(context.Background(), deleteStmt, model.OrgStatusDeleted, deletedAt, tenantId, orgId, version)
+	if errQuery != nil {
+		return 0, errQuery
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+func (orgRepo *OrgDao) RestoreInTx(tx pgx.Tx, tenantId int64, orgId int64, status model.OrganizationStatus) error {
+	restoreStmt := orgRepo.koanf.String("organizations.restore")
+	_, errQuery := tx.Exec(context.Background(), restoreStmt, status, tenantId, orgId)
 	return errQuery
 }
 
-func (orgRepo *OrgDao) FindByCode(code string) (model.Organization, error) {
-	var nilOrg model.Organization
+func (orgRepo *OrgDao) PurgeInTx(tx pgx.Tx, deletedBefore time.Time) (int64, error) {
+	purgeStmt := orgRepo.koanf.String("organizations.purge")
+	cmdTag, errQuery := tx.Exec(context.Background(), purgeStmt, deletedBefore)
+	if errQuery != nil {
+		return 0, errQuery
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+func (orgRepo *OrgDao) FindByCode(tenantId int64, code string) (model.Organization, error) {
 	selStmt := orgRepo.koanf.String("organizations.findbycode")
-	rows, e := orgRepo.dbPool.Query(context.Background(), selStmt, code)
+	return orgRepo.findOne(selStmt, tenantId, code)
+}
+
+func (orgRepo *OrgDao) FindDeletedByCode(tenantId int64, code string) (model.Organization, error) {
+	selStmt := orgRepo.koanf.String("organizations.findbycodedeleted")
+	return orgRepo.findOne(selStmt, tenantId, code)
+}
+
+func (orgRepo *OrgDao) findOne(selStmt string, tenantId int64, code string) (model.Organization, error) {
+	var nilOrg model.Organization
+	rows, e := orgRepo.dbPool.Query(context.Background(), selStmt, tenantId, code)
 	if e != nil {
 		return nilOrg, e
 	}
@@ -79,6 +128,38 @@ func (orgRepo *OrgDao) FindAll(tenantId int64) ([]model.Organization, error) {
 	return orgs, nil
 }
 
+func (orgRepo *OrgDao) FindChildren(tenantId int64, orgId int64) ([]model.Organization, error) {
+	selStmt := orgRepo.koanf.String("organizations.findchildren")
+	rows, errQry := orgRepo.dbPool.Query(context.Background(), selStmt, tenantId, orgId)
+	if errQry != nil {
+		return nil, errQry
+	}
+	defer rows.Close()
+	orgs, errCollect := pgx.CollectRows(rows, pgx.RowToStructByName[model.Organization])
+	if errCollect != nil {
+		return nil, errCollect
+	}
+	return orgs, nil
+}
+
+func (orgRepo *OrgDao) CountChildrenInTx(tx pgx.Tx, orgId int64) (int64, error) {
+	selStmt := orgRepo.koanf.String("organizations.countchildren")
+	var cnt int64
+	errQry := tx.QueryRow(context.Background(), selStmt, orgId).Scan(&cnt)
+	return cnt, errQry
+}
+
+// FindAncestorIds returns the organization id followed by the ids of all its ancestors
+func (orgRepo *OrgDao) FindAncestorIds(orgId int64) ([]int64, error) {
+	selStmt := orgRepo.koanf.String("organizations.findancestorids")
+	rows, errQry := orgRepo.dbPool.Query(context.Background(), selStmt, orgId)
+	if errQry != nil {
+		return nil, errQry
+	}
+	defer rows.Close()
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
 func (orgRepo *OrgDao) ExistsByCode(tenantId int64, code string) (bool, error) {
 	selStmt := orgRepo.koanf.String("organizations.existsbycode")
 	rows, e := orgRepo.dbPool.Query(context.Background(), selStmt, tenantId, code)
@@ -121,3 +202,116 @@ func (orgRepo *OrgDao) ExistsByLabel(tenantId int64, label string) (bool, error)
 	}
 	return exists, nil
 }
+
+func (orgRepo *OrgDao) FindIdByLabel(tenantId int64, label string) (int64, error) {
+	selStmt := orgRepo.koanf.String("organizations.findbylabel")
+	rows, errQry := orgRepo.dbPool.Query(context.Background(), selStmt, tenantId, label)
+	if errQry != nil {
+		return 0, errQry
+	}
+	defer rows.Close()
+	var id int64
+	for rows.Next() {
+		err := rows.Scan(&id)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return id, rows.Err()
+}
+
+func (orgRepo *OrgDao) CountByCriteria(criteria model.OrgFilterCriteria) (int, error) {
+	qryPrefix := "select count(1) from organizations"
+	whereClause, vals := computeOrgCriteriaQuery(qryPrefix, criteria)
+	cnt := 0
+	errCount := orgRepo.dbPool.QueryRow(context.Background(), whereClause, vals...).Scan(&cnt)
+	if errCount != nil {
+		return 0, errCount
+	}
+	return cnt, nil
+}
+
+func (orgRepo *OrgDao) FindByCriteria(criteria model.OrgFilterCriteria) (model.OrgSearchResult, error) {
+	searchResults := model.OrgSearchResult{}
+	var fullQry strings.Builder
+	qryPrefix := orgRepo.koanf.String("organizations.find_by_query")
+	whereClause, vals := computeOrgCriteriaQuery(qryPrefix, criteria)
+	fullQry.WriteString(whereClause)
+
+	// Sort column is whitelisted, code is appended to keep a stable order between pages
+	sortColumn := string(model.OrgSortByLabel)
+	if criteria.SortBy == model.OrgSortByCode {
+		sortColumn = string(model.OrgSortByCode)
+	}
+	sortDirection := " asc"
+	if criteria.SortDesc {
+		sortDirection = " desc"
+	}
+	fullQry.WriteString(" order by ")
+	fullQry.WriteString(sortColumn)
+	fullQry.WriteString(sortDirection)
+	if sortColumn != string(model.OrgSortByCode) {
+		fullQry.WriteString(",code asc")
+	}
+
+	if criteria.Page > 1 {
+		startPg := (criteria.Page - 1) * criteria.RowsPerPage
+		fullQry.WriteString(" offset ")
+		fullQry.WriteString(strconv.Itoa(startPg))
+	}
+	fullQry.WriteString(" limit ")
+	fullQry.WriteString(strconv.Itoa(criteria.RowsPerPage))
+
+	rows, errQuery := orgRepo.dbPool.Query(context.Background(), fullQry.String(), vals...)
+	if errQuery != nil {
+		return searchResults, errQuery
+	}
+	defer rows.Close()
+
+	orgs, errCollect := pgx.CollectRows(rows, pgx.RowToStructByName[model.Organization])
+	if errCollect != nil {
+		return searchResults, errCollect
+	}
+	searchResults.Organizations = orgs
+	return searchResults, nil
+}
+
+func computeOrgCriteriaQuery(qryPrefix string, criteria model.OrgFilterCriteria) (string, []interface{}) {
+	var values []interface{}
+	var buf strings.Builder
+	inc := 1
+
+	buf.WriteString(qryPrefix)
+	buf.WriteString(" where ")
+
+	values = append(values, criteria.TenantId)
+	inc, whereTenant := addCriteria(WhereExprEq, "tenant_id", inc, LogicalOperatorAnd)
+	buf.WriteString(whereTenant)
+	buf.WriteString(" and deleted_at is null")
+
+	if criteria.Type != "" {
+		values = append(values, criteria.Type)
+		nextInc, whereType := addCriteria(WhereExprEq, "type", inc, LogicalOperatorAnd)
+		inc = nextInc
+		buf.WriteString(whereType)
+	}
+	if criteria.Status != nil {
+		values = append(values, *criteria.Status)
+		nextInc, whereStatus := addCriteria(WhereExprEq, "status", inc, LogicalOperatorAnd)
+		inc = nextInc
+		buf.WriteString(whereStatus)
+	}
+	if criteria.Label != "" {
+		values = append(values, containsPattern(criteria.Label))
+		nextInc, whereLabel := addCriteria(WhereExprILike, "label", inc, LogicalOperatorAnd)
+		inc = nextInc
+		buf.WriteString(whereLabel)
+	}
+	if criteria.ParentId != nil {
+		values = append(values, *criteria.ParentId)
+		nextInc, whereParent := addCriteria(WhereExprEq, "parent_id", inc, LogicalOperatorAnd)
+		inc = nextInc
+		buf.WriteString(whereParent)
+	}
+	return buf.String(), values
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"micro-fiber-test/pkg/model"
 	"micro-fiber-test/pkg/repository/api"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -25,23 +26,45 @@ func NewSectorDao(pool *pgxpool.Pool, kSql *koanf.Koanf) api.SectorDaoInterface
 func (s SectorDao) CreateInTx(tx pgx.Tx, sector model.Sector) (int64, error) {
 	var id int64
 	insertStmt := s.koanf.String("sectors.create")
-	errQuery := tx.QueryRow(context.Background(), insertStmt, sector.TenantId, sector.OrgId, sector.Code, sector.Label, sector.ParentId, sector.HasParent, sector.Depth, sector.Status).Scan(&id)
+	errQuery := tx.QueryRow(context.Background(), insertStmt, sector.TenantId, sector.OrgId, sector.Code, sector.Label, sector.ParentId, sector.HasParent, sector.Depth, sector.Status, sector.Position, nullableAttributes(sector.Attributes)).Scan(&id)
 	return id, errQuery
 }
 
 func (s SectorDao) Create(sector model.Sector) (int64, error) {
 	var id int64
 	insertStmt := s.koanf.String("sectors.create")
-	errQuery := s.dbPool.QueryRow(context.Background(), insertStmt, sector.TenantId, sector.OrgId, sector.Code, sector.Label, sector.ParentId, sector.HasParent, sector.Depth, sector.Status).Scan(&id)
+	errQuery := s.dbPool.QueryRow(context.Background(), insertStmt, sector.TenantId, sector.OrgId, sector.Code, sector.Label, sector.ParentId, sector.HasParent, sector.Depth, sector.Status, sector.Position, nullableAttributes(sector.Attributes)).Scan(&id)
 	return id, errQuery
 }
 
-func (s SectorDao) DeleteByOrgId(orgId int64) error {
+func (s SectorDao) DeleteByOrgIdInTx(tx pgx.Tx, orgId int64, deletedAt time.Time) error {
 	deleteStmt := s.koanf.String("sectors.deletebyorgid")
-	_, errDelete := s.dbPool.Exec(context.Background(), deleteStmt, orgId)
+	_, errDelete := tx.Exec(context.Background(), deleteStmt, model.SectorStatusDeleted, deletedAt, orgId)
 	return errDelete
 }
 
+func (s SectorDao) RestoreByOrgIdInTx(tx pgx.Tx, orgId int64, deletedAt time.Time) error {
+	restoreStmt := s.koanf.String("sectors.restorebyorgid")
+	_, errRestore := tx.Exec(context.Background(), restoreStmt, orgId, deletedAt)
+	return errRestore
+}
+
+func (s SectorDao) CountByOrgIdInTx(tx pgx.Tx, orgId int64) (int64, error) {
+	selStmt := s.koanf.String("sectors.countbyorgid")
+	var cnt int64
+	errQry := tx.QueryRow(context.Background(), selStmt, orgId).Scan(&cnt)
+	return cnt, errQry
+}
+
+func (s SectorDao) PurgeInTx(tx pgx.Tx, deletedBefore time.Time) (int64, error) {
+	purgeStmt := s.koanf.String("sectors.purge")
+	cmdTag, errPurge := tx.Exec(context.Background(), purgeStmt, deletedBefore)
+	if errPurge != nil {
+		return 0, errPurge
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
 func (s SectorDao) FindSectorsByTenantOrg(tenantId int64, orgId int64) ([]model.Sector, error) {
 	selStmt := s.koanf.String("sectors.findbytenantorg")
 	rows, e := s.dbPool.Query(context.Background(), selStmt, tenantId, orgId)
@@ -57,9 +80,10 @@ func (s SectorDao) FindSectorsByTenantOrg(tenantId int64, orgId int64) ([]model.
 	return sectors, nil
 }
 
-func (s SectorDao) FindByLabel(defaultTenantId int64, label string) (int64, string, error) {
-	selStmt := s.koanf.String("sectors.findbylabel")
-	rows, errQry := s.dbPool.Query(context.Background(), selStmt, defaultTenantId, label)
+// FindSiblingByLabel returns the id and code of the live child of parentId in the organization carrying the label
+func (s SectorDao) FindSiblingByLabel(defaultTenantId int64, orgId int64, parentId int64, label string) (int64, string, error) {
+	selStmt := s.koanf.String("sectors.findsiblingbylabel")
+	rows, errQry := s.dbPool.Query(context.Background(), selStmt, defaultTenantId, orgId, parentId, label)
 	if errQry != nil {
 		return 0, "", errQry
 	}
@@ -78,8 +102,39 @@ func (s SectorDao) FindByLabel(defaultTenantId int64, label string) (int64, stri
 }
 
 func (s SectorDao) FindByCode(defaultTenantId int64, code string) (model.Sector, error) {
-	var nilSector model.Sector
 	selStmt := s.koanf.String("sectors.findbycode")
+	return s.findOne(selStmt, defaultTenantId, code)
+}
+
+func (s SectorDao) FindById(defaultTenantId int64, sectorId int64) (model.Sector, error) {
+	selStmt := s.koanf.String("sectors.findbyid")
+	sectorsList, errQry := s.findList(selStmt, defaultTenantId, sectorId)
+	if errQry != nil {
+		return model.Sector{}, errQry
+	}
+	if len(sectorsList) == 0 {
+		return model.Sector{}, pgx.ErrNoRows
+	}
+	return sectorsList[0], nil
+}
+
+func (s SectorDao) FindDeletedByCode(defaultTenantId int64, code string) (model.Sector, error) {
+	selStmt := s.koanf.String("sectors.findbycodedeleted")
+	return s.findOne(selStmt, defaultTenantId, code)
+}
+
+func (s SectorDao) ExistsById(defaultTenantId int64, sectorId int64) (bool, error) {
+	selStmt := s.koanf.String("sectors.existsbyid")
+	cnt := 0
+	errQry := s.dbPool.QueryRow(context.Background(), selStmt, defaultTenantId, sectorId).Scan(&cnt)
+	if errQry != nil {
+		return false, errQry
+	}
+	return cnt > 0, nil
+}
+
+func (s SectorDao) findOne(selStmt string, defaultTenantId int64, code string) (model.Sector, error) {
+	var nilSector model.Sector
 	rows, errQry := s.dbPool.Query(context.Background(), selStmt, defaultTenantId, code)
 	if errQry != nil {
 		return nilSector, errQry
@@ -116,17 +171,271 @@ func (s SectorDao) FindRootSector(defaultTenantId int64, orgId int64) (int64, er
 	return sectorId, nil
 }
 
-func (s SectorDao) DeleteSector(defaultTenantId int64, sectorId int64) error {
+// DeleteInTx soft deletes the sector alone and returns the number of deleted rows
+func (s SectorDao) DeleteInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, deletedAt time.Time, version int64) (int64, error) {
 	deleteStmt := s.koanf.String("sectors.delete")
-	_, e := s.dbPool.Exec(context.Background(), deleteStmt, defaultTenantId, sectorId, sectorId)
+	cmdTag, e := tx.Exec(context.Background(), deleteStmt, model.SectorStatusDeleted, deletedAt, defaultTenantId, sectorId, version)
 	if e != nil {
-		return e
+		return 0, e
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+// DeleteSubtreeInTx soft deletes the sector and all its live descendants with the same timestamp and returns the number of deleted rows
+func (s SectorDao) DeleteSubtreeInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, deletedAt time.Time, version int64) (int64, error) {
+	deleteStmt := s.koanf.String("sectors.deletesubtree")
+	cmdTag, e := tx.Exec(context.Background(), deleteStmt, model.SectorStatusDeleted, deletedAt, defaultTenantId, sectorId, version)
+	if e != nil {
+		return 0, e
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+// ReparentChildrenInTx attaches the live children of a sector to another parent and returns their ids
+func (s SectorDao) ReparentChildrenInTx(tx pgx.Tx, sectorId int64, parentId int64) ([]int64, error) {
+	updateStmt := s.koanf.String("sectors.reparentchildren")
+	rows, errQuery := tx.Query(context.Background(), updateStmt, parentId, sectorId)
+	if errQuery != nil {
+		return nil, errQuery
 	}
+	defer rows.Close()
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+func (s SectorDao) CountMembersInTx(tx pgx.Tx, sectorId int64) (int64, error) {
+	selStmt := s.koanf.String("sectors.countmembers")
+	var cnt int64
+	errQry := tx.QueryRow(context.Background(), selStmt, sectorId).Scan(&cnt)
+	return cnt, errQry
+}
+
+// CountMembersBySector returns the number of live users assigned to each live sector of the organization, sectors without members are absent
+func (s SectorDao) CountMembersBySector(defaultTenantId int64, orgId int64) (map[int64]int64, error) {
+	selStmt := s.koanf.String("sectors.countmembersbysector")
+	rows, errQry := s.dbPool.Query(context.Background(), selStmt, defaultTenantId, orgId)
+	if errQry != nil {
+		return nil, errQry
+	}
+	defer rows.Close()
+	counts := make(map[int64]int64)
+	for rows.Next() {
+		var sectorId, cnt int64
+		if errScan := rows.Scan(&sectorId, &cnt); errScan != nil {
+			return nil, errScan
+		}
+		counts[sectorId] = cnt
+	}
+	return counts, rows.Err()
+}
+
+func (s SectorDao) CountSubtreeMembersInTx(tx pgx.Tx, sectorId int64) (int64, error) {
+	selStmt := s.koanf.String("sectors.countsubtreemembers")
+	var cnt int64
+	errQry := tx.QueryRow(context.Background(), selStmt, sectorId).Scan(&cnt)
+	return cnt, errQry
+}
+
+// RestoreSectorInTx restores the sector and the descendants deleted along with it in their status before deletion
+func (s SectorDao) RestoreSectorInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, deletedAt time.Time) error {
+	restoreStmt := s.koanf.String("sectors.restore")
+	_, e := tx.Exec(context.Background(), restoreStmt, defaultTenantId, sectorId, deletedAt)
 	return e
 }
 
-func (s SectorDao) Update(defaultTenantId int64, id int64, label string) error {
+// Update sets the label, and the attributes unless nil, of the sector
+func (s SectorDao) Update(defaultTenantId int64, id int64, label string, attributes map[string]any, version int64) (int64, error) {
 	updateStmt := s.koanf.String("sectors.update")
-	_, errQuery := s.dbPool.Exec(context.Background(), updateStmt, label, id, defaultTenantId)
+	cmdTag, errQuery := s.dbPool.Exec(context.Background(), updateStmt, label, id, defaultTenantId, version, nullableAttributes(attributes))
+	if errQuery != nil {
+		return 0, errQuery
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+// FindSubtree returns the live sector and its live descendants down to maxDepth (absolute depth, negative for no limit), parents first
+func (s SectorDao) FindSubtree(defaultTenantId int64, sectorId int64, maxDepth int) ([]model.Sector, error) {
+	selStmt := s.koanf.String("sectors.findsubtree")
+	return s.findList(selStmt, defaultTenantId, sectorId, maxDepth)
+}
+
+// FindAncestors returns the ancestors of the sector, from the root sector down to its parent
+func (s SectorDao) FindAncestors(defaultTenantId int64, sectorId int64) ([]model.Sector, error) {
+	selStmt := s.koanf.String("sectors.findancestors")
+	return s.findList(selStmt, defaultTenantId, sectorId)
+}
+
+func (s SectorDao) findList(selStmt string, args ...any) ([]model.Sector, error) {
+	rows, errQry := s.dbPool.Query(context.Background(), selStmt, args...)
+	if errQry != nil {
+		return nil, errQry
+	}
+	defer rows.Close()
+	return pgx.CollectRows(rows, pgx.RowToStructByName[model.Sector])
+}
+
+// IsDescendantOf tells whether the sector belongs to the subtree of the ancestor, the ancestor itself included
+func (s SectorDao) IsDescendantOf(defaultTenantId int64, sectorId int64, ancestorId int64) (bool, error) {
+	selStmt := s.koanf.String("sectors.isdescendant")
+	cnt := 0
+	errQry := s.dbPool.QueryRow(context.Background(), selStmt, defaultTenantId, sectorId, ancestorId).Scan(&cnt)
+	if errQry != nil {
+		return false, errQry
+	}
+	return cnt > 0, nil
+}
+
+func (s SectorDao) MoveInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, parentId int64, version int64) (int64, error) {
+	moveStmt := s.koanf.String("sectors.move")
+	cmdTag, errQuery := tx.Exec(context.Background(), moveStmt, parentId, defaultTenantId, sectorId, version)
+	if errQuery != nil {
+		return 0, errQuery
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+// RebaseSubtreeInTx recomputes the path and depth of the sector and all its descendants below a new parent
+func (s SectorDao) RebaseSubtreeInTx(tx pgx.Tx, sectorId int64, parentId int64) error {
+	updateStmt := s.koanf.String("sectors.rebasesubtree")
+	_, errQuery := tx.Exec(context.Background(), updateStmt, sectorId, parentId)
 	return errQuery
 }
+
+// FindSectorsByStatus returns the live sectors of the organization whose status and ancestors' statuses are all in statuses, the root sector being always kept
+func (s SectorDao) FindSectorsByStatus(defaultTenantId int64, orgId int64, statuses []int64) ([]model.Sector, error) {
+	selStmt := s.koanf.String("sectors.findbystatus")
+	return s.findList(selStmt, defaultTenantId, orgId, statuses)
+}
+
+func (s SectorDao) UpdateStatusInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, status model.SectorStatus, version int64) (int64, error) {
+	updateStmt := s.koanf.String("sectors.updatestatus")
+	cmdTag, errQuery := tx.Exec(context.Background(), updateStmt, status, defaultTenantId, sectorId, version)
+	if errQuery != nil {
+		return 0, errQuery
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+// UpdateDescendantsStatusInTx applies the status to the live descendants of the sector and returns the number of changed sectors
+func (s SectorDao) UpdateDescendantsStatusInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, status model.SectorStatus) (int64, error) {
+	updateStmt := s.koanf.String("sectors.updatedescendantsstatus")
+	cmdTag, errQuery := tx.Exec(context.Background(), updateStmt, status, defaultTenantId, sectorId)
+	if errQuery != nil {
+		return 0, errQuery
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+// CountActiveMembersInTx counts the assignments of active users to the sector, or to its whole live subtree when withDescendants is set
+func (s SectorDao) CountActiveMembersInTx(tx pgx.Tx, sectorId int64, withDescendants bool) (int64, error) {
+	selStmt := s.koanf.String("sectors.countactivemembers")
+	var cnt int64
+	errQry := tx.QueryRow(context.Background(), selStmt, sectorId, model.UserStatusActive, withDescendants).Scan(&cnt)
+	return cnt, errQry
+}
+
+// FindCodesInUse returns the codes already used by sectors of other organizations of the tenant or by deleted sectors
+func (s SectorDao) FindCodesInUse(defaultTenantId int64, orgId int64, codes []string) ([]string, error) {
+	selStmt := s.koanf.String("sectors.codesinuse")
+	rows, errQry := s.dbPool.Query(context.Background(), selStmt, defaultTenantId, codes, orgId)
+	if errQry != nil {
+		return nil, errQry
+	}
+	defer rows.Close()
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// ImportUpdateInTx sets the label, status and attributes (kept when nil) of the sector and returns 1 when one of them changed
+func (s SectorDao) ImportUpdateInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, label string, status model.SectorStatus, attributes map[string]any) (int64, error) {
+	updateStmt := s.koanf.String("sectors.importupdate")
+	cmdTag, errQuery := tx.Exec(context.Background(), updateStmt, label, status, defaultTenantId, sectorId, nullableAttributes(attributes))
+	if errQuery != nil {
+		return 0, errQuery
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+func (s SectorDao) CountChildrenInTx(tx pgx.Tx, parentId int64) (int, error) {
+	selStmt := s.koanf.String("sectors.countchildren")
+	cnt := 0
+	errQry := tx.QueryRow(context.Background(), selStmt, parentId).Scan(&cnt)
+	return cnt, errQry
+}
+
+// FindChildren returns the live children of the sector ordered by position
+func (s SectorDao) FindChildren(defaultTenantId int64, parentId int64) ([]model.Sector, error) {
+	selStmt := s.koanf.String("sectors.findchildren")
+	return s.findList(selStmt, defaultTenantId, parentId)
+}
+
+// ShiftPositionsInTx moves the children of the sector from the given position one slot down to free it
+func (s SectorDao) ShiftPositionsInTx(tx pgx.Tx, parentId int64, fromPosition int) error {
+	updateStmt := s.koanf.String("sectors.shiftpositions")
+	_, errQuery := tx.Exec(context.Background(), updateStmt, parentId, fromPosition)
+	return errQuery
+}
+
+// CompactPositionsInTx renumbers the live children of the sector from 0 without gaps, keeping their order
+func (s SectorDao) CompactPositionsInTx(tx pgx.Tx, parentId int64) error {
+	updateStmt := s.koanf.String("sectors.compactpositions")
+	_, errQuery := tx.Exec(context.Background(), updateStmt, parentId)
+	return errQuery
+}
+
+// ReorderChildren sets the position of each child of the sector to its index in codes
+func (s SectorDao) ReorderChildren(defaultTenantId int64, parentId int64, codes []string) error {
+	updateStmt := s.koanf.String("sectors.reorderchildren")
+	_, errQuery := s.dbPool.Exec(context.Background(), updateStmt, defaultTenantId, parentId, codes)
+	return errQuery
+}
+
+func (s SectorDao) AddMember(defaultTenantId int64, sectorId int64, userId int64) error {
+	insertStmt := s.koanf.String("sectors.addmember")
+	_, errQuery := s.dbPool.Exec(context.Background(), insertStmt, defaultTenantId, userId, sectorId)
+	return errQuery
+}
+
+func (s SectorDao) RemoveMember(defaultTenantId int64, sectorId int64, userId int64) error {
+	deleteStmt := s.koanf.String("sectors.removemember")
+	_, errQuery := s.dbPool.Exec(context.Background(), deleteStmt, defaultTenantId, userId, sectorId)
+	return errQuery
+}
+
+// MoveMembersInTx moves every assignment of the first sector to the second one,
+// users already assigned to the second sector only losing their assignment to the first one
+func (s SectorDao) MoveMembersInTx(tx pgx.Tx, fromSectorId int64, toSectorId int64) error {
+	moveStmt := s.koanf.String("sectors.movemembers")
+	_, errQuery := tx.Exec(context.Background(), moveStmt, fromSectorId, toSectorId)
+	return errQuery
+}
+
+// MoveMemberInTx moves the assignment of the user from the first sector to the second one, 0 is returned when the user was not assigned
+func (s SectorDao) MoveMemberInTx(tx pgx.Tx, userId int64, fromSectorId int64, toSectorId int64) (int64, error) {
+	moveStmt := s.koanf.String("sectors.movemember")
+	cmdTag, errQuery := tx.Exec(context.Background(), moveStmt, userId, fromSectorId, toSectorId)
+	if errQuery != nil {
+		return 0, errQuery
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+// UpdateAttributesInTx replaces the attributes of the live sector
+func (s SectorDao) UpdateAttributesInTx(tx pgx.Tx, sectorId int64, attributes map[string]any) error {
+	updateStmt := s.koanf.String("sectors.updateattributes")
+	_, errQuery := tx.Exec(context.Background(), updateStmt, sectorId, attributes)
+	return errQuery
+}
+
+// DeleteOrgMembershipsInTx removes the assignments of the live users of the organization to its sectors
+func (s SectorDao) DeleteOrgMembershipsInTx(tx pgx.Tx, orgId int64) error {
+	deleteStmt := s.koanf.String("sectors.deleteorgmemberships")
+	_, errQuery := tx.Exec(context.Background(), deleteStmt, orgId)
+	return errQuery
+}
+
+// nullableAttributes sends nil attributes as a SQL null rather than a JSON null
+func nullableAttributes(attributes map[string]any) any {
+	if attributes == nil {
+		return nil
+	}
+	return attributes
+}
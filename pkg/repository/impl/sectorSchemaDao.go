@@ -0,0 +1,52 @@
+package impl
+
+import (
+	"context"
+	"micro-fiber-test/pkg/model"
+	"micro-fiber-test/pkg/repository/api"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knadh/koanf"
+)
+
+type SectorSchemaDao struct {
+	dbPool *pgxpool.Pool
+	koanf  *koanf.Koanf
+}
+
+func NewSectorSchemaDao(pool *pgxpool.Pool, kSql *koanf.Koanf) api.SectorSchemaDaoInterface {
+	schemaDao := SectorSchemaDao{}
+	schemaDao.dbPool = pool
+	schemaDao.koanf = kSql
+	return &schemaDao
+}
+
+func (d SectorSchemaDao) FindByOrgId(tenantId int64, orgId int64) (model.SectorAttributeSchema, error) {
+	var nilSchema model.SectorAttributeSchema
+	selStmt := d.koanf.String("sectorschemas.findbyorgid")
+	rows, errQry := d.dbPool.Query(context.Background(), selStmt, tenantId, orgId)
+	if errQry != nil {
+		return nilSchema, errQry
+	}
+	defer rows.Close()
+	schema, errCollect := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.SectorAttributeSchema])
+	if errCollect != nil {
+		return nilSchema, errCollect
+	}
+	return schema, nil
+}
+
+// Save creates or replaces the attribute schema of the organization
+func (d SectorSchemaDao) Save(schema model.SectorAttributeSchema) error {
+	saveStmt := d.koanf.String("sectorschemas.save")
+	_, errQuery := d.dbPool.Exec(context.Background(), saveStmt, schema.TenantId, schema.OrgId, schema.Attributes)
+	return errQuery
+}
+
+// CopyInTx gives the target organization the attribute schema of the source organization, nothing is copied when the source has none
+func (d SectorSchemaDao) CopyInTx(tx pgx.Tx, fromOrgId int64, toOrgId int64) error {
+	copyStmt := d.koanf.String("sectorschemas.copy")
+	_, errQuery := tx.Exec(context.Background(), copyStmt, fromOrgId, toOrgId)
+	return errQuery
+}
@@ -0,0 +1,75 @@
+package impl
+
+import (
+	"context"
+	"micro-fiber-test/pkg/model"
+	"micro-fiber-test/pkg/repository/api"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knadh/koanf"
+)
+
+type TemplateDao struct {
+	dbPool *pgxpool.Pool
+	koanf  *koanf.Koanf
+}
+
+func NewTemplateDao(pool *pgxpool.Pool, kSql *koanf.Koanf) api.TemplateDaoInterface {
+	templateDao := TemplateDao{}
+	templateDao.dbPool = pool
+	templateDao.koanf = kSql
+	return &templateDao
+}
+
+func (t TemplateDao) Create(template model.SectorTemplate) (int64, error) {
+	var id int64
+	insertStmt := t.koanf.String("templates.create")
+	errQuery := t.dbPool.QueryRow(context.Background(), insertStmt, template.TenantId, template.Code, template.Label, template.Nodes).Scan(&id)
+	return id, errQuery
+}
+
+func (t TemplateDao) Delete(tenantId int64, code string) error {
+	deleteStmt := t.koanf.String("templates.delete")
+	_, errQuery := t.dbPool.Exec(context.Background(), deleteStmt, tenantId, code)
+	return errQuery
+}
+
+func (t TemplateDao) FindByCode(tenantId int64, code string) (model.SectorTemplate, error) {
+	var nilTemplate model.SectorTemplate
+	selStmt := t.koanf.String("templates.findbycode")
+	rows, errQry := t.dbPool.Query(context.Background(), selStmt, tenantId, code)
+	if errQry != nil {
+		return nilTemplate, errQry
+	}
+	defer rows.Close()
+	template, errCollect := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.SectorTemplate])
+	if errCollect != nil {
+		return nilTemplate, errCollect
+	}
+	return template, nil
+}
+
+func (t TemplateDao) FindAll(tenantId int64) ([]model.SectorTemplate, error) {
+	selStmt := t.koanf.String("templates.findall")
+	rows, errQry := t.dbPool.Query(context.Background(), selStmt, tenantId)
+	if errQry != nil {
+		return nil, errQry
+	}
+	defer rows.Close()
+	templates, errCollect := pgx.CollectRows(rows, pgx.RowToStructByName[model.SectorTemplate])
+	if errCollect != nil {
+		return nil, errCollect
+	}
+	return templates, nil
+}
+
+func (t TemplateDao) ExistsByLabel(tenantId int64, label string) (bool, error) {
+	selStmt := t.koanf.String("templates.existsbylabel")
+	cnt := 0
+	errQry := t.dbPool.QueryRow(context.Background(), selStmt, tenantId, label).Scan(&cnt)
+	if errQry != nil {
+		return false, errQry
+	}
+	return cnt > 0, nil
+}
@@ -0,0 +1,123 @@
+package impl
+
+import (
+	"context"
+	"micro-fiber-test/pkg/model"
+	"micro-fiber-test/pkg/repository/api"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/knadh/koanf"
+)
+
+type TenantDao struct {
+	dbPool *pgxpool.Pool
+	koanf  *koanf.Koanf
+}
+
+func NewTenantDao(pool *pgxpool.Pool, kSql *koanf.Koanf) api.TenantDaoInterface {
+	tenantDao := TenantDao{}
+	tenantDao.dbPool = pool
+	tenantDao.koanf = kSql
+	return &tenantDao
+}
+
+func (t TenantDao) Create(tenant model.Tenant) (int64, error) {
+	var id int64
+	insertStmt := t.koanf.String("tenants.create")
+	errQuery := t.dbPool.QueryRow(context.Background(), insertStmt, tenant.Code, tenant.Label, tenant.Status).Scan(&id)
+	return id, errQuery
+}
+
+func (t TenantDao) Update(code string, label string) error {
+	updateStmt := t.koanf.String("tenants.update")
+	_, errQuery := t.dbPool.Exec(context.Background(), updateStmt, label, code)
+	return errQuery
+}
+
+func (t TenantDao) UpdateStatus(code string, status model.TenantStatus) error {
+	updateStmt := t.koanf.String("tenants.updatestatus")
+	_, errQuery := t.dbPool.Exec(context.Background(), updateStmt, status, code)
+	return errQuery
+}
+
+func (t TenantDao) Delete(code string) error {
+	deleteStmt := t.koanf.String("tenants.delete")
+	_, errQuery := t.dbPool.Exec(context.Background(), deleteStmt, code)
+	return errQuery
+}
+
+func (t TenantDao) FindAll() ([]model.Tenant, error) {
+	selStmt := t.koanf.String("tenants.findall")
+	rows, errQry := t.dbPool.Query(context.Background(), selStmt)
+	if errQry != nil {
+		return nil, errQry
+	}
+	defer rows.Close()
+	tenants, errCollect := pgx.CollectRows(rows, pgx.RowToStructByName[model.Tenant])
+	if errCollect != nil {
+		return nil, errCollect
+	}
+	return tenants, nil
+}
+
+func (t TenantDao) ExistsByCode(code string) (bool, error) {
+	selStmt := t.koanf.String("tenants.existsbycode")
+	cnt, err := t.count(selStmt, code)
+	if err != nil {
+		return false, err
+	}
+	return cnt > 0, nil
+}
+
+func (t TenantDao) CountOrganizations(tenantId int64) (int, error) {
+	selStmt := t.koanf.String("tenants.countorgs")
+	return t.count(selStmt, tenantId)
+}
+
+// CountSectorSettings counts the sector templates and sector attribute schemas of the tenant
+func (t TenantDao) CountSectorSettings(tenantId int64) (int, error) {
+	selStmt := t.koanf.String("tenants.countsectorsettings")
+	return t.count(selStmt, tenantId)
+}
+
+// FindCodesByUserLogin lists the codes of the tenants holding a non deleted user with this login
+func (t TenantDao) FindCodesByUserLogin(login string) ([]string, error) {
+	selStmt := t.koanf.String("tenants.findcodesbyuserlogin")
+	rows, errQry := t.dbPool.Query(context.Background(), selStmt, login)
+	if errQry != nil {
+		return nil, errQry
+	}
+	defer rows.Close()
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (t TenantDao) FindById(id int64) (model.Tenant, error) {
+	selStmt := t.koanf.String("tenants.findbyid")
+	return t.findOne(selStmt, id)
+}
+
+func (t TenantDao) FindByCode(code string) (model.Tenant, error) {
+	selStmt := t.koanf.String("tenants.findbycode")
+	return t.findOne(selStmt, code)
+}
+
+func (t TenantDao) findOne(selStmt string, arg interface{}) (model.Tenant, error) {
+	var nilTenant model.Tenant
+	rows, errQry := t.dbPool.Query(context.Background(), selStmt, arg)
+	if errQry != nil {
+		return nilTenant, errQry
+	}
+	defer rows.Close()
+	tenant, errCollect := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Tenant])
+	if errCollect != nil {
+		return nilTenant, errCollect
+	}
+	return tenant, nil
+}
+
+func (t TenantDao) count(selStmt string, arg interface{}) (int, error) {
+	cnt := 0
+	errQuery := t.dbPool.QueryRow(context.Background(), selStmt, arg).Scan(&cnt)
+	return cnt, errQuery
+}
@@ -7,6 +7,7 @@ import (
 	"micro-fiber-test/pkg/repository/api"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -22,8 +23,17 @@ const (
 	WhereExprNotIn     = "%s not in(%s)"
 	WhereExprLike      = "%s like %s"
 	WhereExprNotLike   = "%s not like %s"
+	WhereExprILike     = "%s ilike %s escape '\\'"
 )
 
+// likeEscaper escapes the pattern metacharacters of a value matched with the backslash escape character
+var likeEscaper = strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+
+// containsPattern returns the pattern matching the values containing value literally
+func containsPattern(value string) string {
+	return "%" + likeEscaper.Replace(value) + "%"
+}
+
 type UserDao struct {
 	dbPool *pgxpool.Pool
 	koanf  *koanf.Koanf
@@ -43,10 +53,14 @@ func (u UserDao) Create(user model.User) (int64, error) {
 	return id, errQuery
 }
 
-func (u UserDao) Update(user model.User) error {
+// Update applies the user attributes when user.Version is the current version (0 skips the check) and returns the number of updated rows
+func (u UserDao) Update(user model.User) (int64, error) {
 	updateStmt := u.koanf.String("users.update_by_external_id")
-	_, errQuery := u.dbPool.Exec(context.Background(), updateStmt, user.LastName, user.FirstName, user.MiddleName, user.Login, user.Email, user.ExternalId)
-	return errQuery
+	cmdTag, errQuery := u.dbPool.Exec(context.Background(), updateStmt, user.LastName, user.FirstName, user.MiddleName, user.Login, user.Email, user.TenantId, user.ExternalId, user.Version)
+	if errQuery != nil {
+		return 0, errQuery
+	}
+	return cmdTag.RowsAffected(), nil
 }
 
 func (u UserDao) CountByCriteria(criteria model.UserFilterCriteria) (int, error) {
@@ -105,6 +119,22 @@ func (u UserDao) FindByCriteria(criteria model.UserFilterCriteria) (model.UserSe
 
 func (u UserDao) FindByExternalId(tenantId int64, orgId int64, externalId string) (model.User, error) {
 	qry := u.koanf.String("users.find_by_external_id")
+	return u.findOne(qry, tenantId, orgId, externalId)
+}
+
+func (u UserDao) FindDeletedByExternalId(tenantId int64, orgId int64, externalId string) (model.User, error) {
+	qry := u.koanf.String("users.find_deleted_by_external_id")
+	return u.findOne(qry, tenantId, orgId, externalId)
+}
+
+func (u UserDao) FindIdByExternalId(tenantId int64, externalId string) (int64, error) {
+	qry := u.koanf.String("users.find_id_by_external_id")
+	var id int64
+	errQuery := u.dbPool.QueryRow(context.Background(), qry, tenantId, externalId).Scan(&id)
+	return id, errQuery
+}
+
+func (u UserDao) findOne(qry string, tenantId int64, orgId int64, externalId string) (model.User, error) {
 	var nilUser model.User
 	rows, errQuery := u.dbPool.Query(context.Background(), qry, tenantId, orgId, externalId)
 	if errQuery != nil {
@@ -119,9 +149,9 @@ func (u UserDao) FindByExternalId(tenantId int64, orgId int64, externalId string
 	return userInterface, nil
 }
 
-func (u UserDao) IsLoginInUse(login string) (int64, string, error) {
+func (u UserDao) IsLoginInUse(tenantId int64, login string) (int64, string, error) {
 	selStmt := u.koanf.String("users.find_by_login")
-	rows, errQuery := u.dbPool.Query(context.Background(), selStmt, login)
+	rows, errQuery := u.dbPool.Query(context.Background(), selStmt, tenantId, login)
 	if errQuery != nil {
 		return 0, "", errQuery
 	}
@@ -139,9 +169,9 @@ func (u UserDao) IsLoginInUse(login string) (int64, string, error) {
 	return 0, "", nil
 }
 
-func (u UserDao) IsEmailInUse(email string) (int64, string, error) {
+func (u UserDao) IsEmailInUse(tenantId int64, email string) (int64, string, error) {
 	selStmt := u.koanf.String("users.email_in_user")
-	rows, errQuery := u.dbPool.Query(context.Background(), selStmt, email)
+	rows, errQuery := u.dbPool.Query(context.Background(), selStmt, tenantId, email)
 	if errQuery != nil {
 		return 0, "", errQuery
 	}
@@ -159,13 +189,52 @@ func (u UserDao) IsEmailInUse(email string) (int64, string, error) {
 	return 0, "", nil
 }
 
-func (u UserDao) Delete(userExtId string) error {
-	selStmt := u.koanf.String("users.delete_by_external_id")
-	rows, errQuery := u.dbPool.Query(context.Background(), selStmt, userExtId)
+func (u UserDao) Delete(tenantId int64, userExtId string, version int64) (int64, error) {
+	deleteStmt := u.koanf.String("users.delete_by_external_id")
+	cmdTag, errQuery := u.dbPool.Exec(context.Background(), deleteStmt, model.UserStatusDeleted, tenantId, userExtId, version)
 	if errQuery != nil {
-		return errQuery
+		return 0, errQuery
 	}
-	defer rows.Close()
+	return cmdTag.RowsAffected(), nil
+}
+
+func (u UserDao) Restore(tenantId int64, userExtId string) error {
+	restoreStmt := u.koanf.String("users.restore_by_external_id")
+	_, errQuery := u.dbPool.Exec(context.Background(), restoreStmt, tenantId, userExtId)
+	return errQuery
+}
+
+func (u UserDao) PurgeInTx(tx pgx.Tx, deletedBefore time.Time) (int64, error) {
+	purgeStmt := u.koanf.String("users.purge")
+	cmdTag, errQuery := tx.Exec(context.Background(), purgeStmt, deletedBefore)
+	if errQuery != nil {
+		return 0, errQuery
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+func (u UserDao) CountByOrgIdInTx(tx pgx.Tx, orgId int64) (int64, error) {
+	selStmt := u.koanf.String("users.count_by_org")
+	var cnt int64
+	errQuery := tx.QueryRow(context.Background(), selStmt, orgId).Scan(&cnt)
+	return cnt, errQuery
+}
+
+func (u UserDao) DeleteByOrgIdInTx(tx pgx.Tx, orgId int64, deletedAt time.Time) error {
+	deleteStmt := u.koanf.String("users.delete_by_org")
+	_, errQuery := tx.Exec(context.Background(), deleteStmt, model.UserStatusDeleted, deletedAt, orgId)
+	return errQuery
+}
+
+func (u UserDao) RestoreByOrgIdInTx(tx pgx.Tx, orgId int64, deletedAt time.Time) error {
+	restoreStmt := u.koanf.String("users.restore_by_org")
+	_, errQuery := tx.Exec(context.Background(), restoreStmt, orgId, deletedAt)
+	return errQuery
+}
+
+func (u UserDao) MoveToOrgInTx(tx pgx.Tx, fromOrgId int64, toOrgId int64) error {
+	updateStmt := u.koanf.String("users.move_to_org")
+	_, errQuery := tx.Exec(context.Background(), updateStmt, toOrgId, fromOrgId)
 	return errQuery
 }
 
@@ -185,6 +254,7 @@ func computeFindByCriteriaQuery(qryPrefix string, criteria model.UserFilterCrite
 	values = append(values, criteria.OrgId)
 	inc, whereOrg := addCriteria(WhereExprEq, "org_id", inc, LogicalOperatorAnd)
 	buf.WriteString(whereOrg)
+	buf.WriteString(" and deleted_at is null")
 
 	if criteria.Login != "" {
 		values = append(values, "%"+criteria.Login+"%")
@@ -0,0 +1,13 @@
+package impl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainsPattern(t *testing.T) {
+	assert.Equal(t, "%sales%", containsPattern("sales"))
+	assert.Equal(t, "%100\\% sales\\_eu%", containsPattern("100% sales_eu"))
+	assert.Equal(t, "%a\\\\b%", containsPattern("a\\b"))
+}
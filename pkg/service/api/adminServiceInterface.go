@@ -0,0 +1,7 @@
+package api
+
+import "micro-fiber-test/pkg/model"
+
+type AdminServiceInterface interface {
+	Purge(retentionDays int) (model.PurgeReport, error)
+}
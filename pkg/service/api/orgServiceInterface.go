@@ -3,9 +3,14 @@ package api
 import "micro-fiber-test/pkg/model"
 
 type OrganizationServiceInterface interface {
-	Create(cnxParams string, defautTenantId int64, organization model.Organization) (int64, error)
-	Update(defautTenantId int64, orgCode string, label string) error
-	Delete(defautTenantId int64, orgCode string) error
+	Create(cnxParams string, defautTenantId int64, organization model.Organization, templateCode string) (int64, error)
+	Clone(defautTenantId int64, orgCode string, options model.OrgCloneOptions) (string, error)
+	Update(defautTenantId int64, orgCode string, label string, version int64) error
+	Patch(defautTenantId int64, orgCode string, patch model.OrganizationPatch, version int64) error
+	ChangeStatus(defautTenantId int64, orgCode string, action model.OrgLifecycleAction) error
+	Delete(defautTenantId int64, orgCode string, options model.OrgDeleteOptions) (model.OrgDeletionReport, error)
 	FindByCode(defautTenantId int64, code string) (model.Organization, error)
+	FindChildren(defautTenantId int64, code string) ([]model.Organization, error)
 	FindAll(defautTenantId int64) ([]model.Organization, error)
+	FindByCriteria(criteria model.OrgFilterCriteria) (model.OrgSearchResult, error)
 }
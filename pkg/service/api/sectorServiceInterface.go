@@ -1,13 +1,32 @@
 package api
 
-import "micro-fiber-test/pkg/model"
+import (
+	"micro-fiber-test/pkg/model"
+	"micro-fiber-test/pkg/validation"
+)
 
 type SectorServiceInterface interface {
 	Create(defautTenantId int64, sector model.Sector) (int64, error)
-	Update(defaultTenantId int64, id int64, label string) error
-	FindSectorsByTenantOrg(defaultTenantId int64, orgId int64) ([]model.Sector, error)
+	CreateTree(defaultTenantId int64, orgId int64, parentCode string, nodes []model.SectorTreeNode) ([]model.SectorTreeNode, error)
+	Import(defaultTenantId int64, orgId int64, rows []model.SectorImportRow) (model.SectorImportReport, error)
+	Update(defaultTenantId int64, sector model.Sector, label string, attributes map[string]any, version int64) error
+	FindAttributeSchema(defaultTenantId int64, orgId int64) ([]model.SectorAttributeDef, error)
+	SaveAttributeSchema(defaultTenantId int64, orgId int64, defs []model.SectorAttributeDef) error
+	ValidateAttributes(defaultTenantId int64, orgId int64, values map[string]any) ([]validation.ErrorValidation, error)
+	FindSectorsByTenantOrg(defaultTenantId int64, orgId int64, statuses []model.SectorStatus) ([]model.Sector, error)
 	FindByCode(defaultTenantId int64, code string) (model.Sector, error)
+	FindByLabelPath(defaultTenantId int64, orgId int64, path string) (model.Sector, error)
+	FindSubtree(defaultTenantId int64, orgId int64, code string, maxDepth int) ([]model.Sector, error)
+	FindAncestors(defaultTenantId int64, sectorId int64) ([]model.Sector, error)
 	FindRootSectorId(defaultTenantId int64, orgId int64) (int64, error)
-	DeleteSector(defaultTenantId int64, sectorId int64) error
-	FindByLabel(defaultTenantId int64, label string) (int64, string, error)
+	CountMembersBySector(defaultTenantId int64, orgId int64) (map[int64]int64, error)
+	DeleteSector(defaultTenantId int64, sector model.Sector, options model.SectorDeleteOptions) (model.SectorDeletionReport, error)
+	Restore(defaultTenantId int64, orgId int64, code string) error
+	Move(defaultTenantId int64, orgId int64, code string, parentCode string, version int64) error
+	Merge(defaultTenantId int64, orgId int64, code string, options model.SectorMergeOptions) (model.SectorReorganisationReport, error)
+	Split(defaultTenantId int64, orgId int64, code string, options model.SectorSplitOptions) (model.SectorReorganisationReport, error)
+	ChangeStatus(defaultTenantId int64, orgId int64, code string, status model.SectorStatus, options model.SectorStatusOptions) error
+	Reorder(defaultTenantId int64, orgId int64, code string, childCodes []string) error
+	AddMember(defaultTenantId int64, sectorId int64, userExtId string) error
+	RemoveMember(defaultTenantId int64, sectorId int64, userExtId string) error
 }
@@ -0,0 +1,10 @@
+package api
+
+import "micro-fiber-test/pkg/model"
+
+type TemplateServiceInterface interface {
+	Create(tenantId int64, template model.SectorTemplate) (int64, error)
+	Delete(tenantId int64, code string) error
+	FindByCode(tenantId int64, code string) (model.SectorTemplate, error)
+	FindAll(tenantId int64) ([]model.SectorTemplate, error)
+}
@@ -0,0 +1,15 @@
+package api
+
+import "micro-fiber-test/pkg/model"
+
+type TenantServiceInterface interface {
+	Create(tenant model.Tenant) (int64, error)
+	Rename(code string, label string) error
+	Suspend(code string) error
+	Activate(code string) error
+	Delete(code string) error
+	FindById(id int64) (model.Tenant, error)
+	FindByCode(code string) (model.Tenant, error)
+	FindAll() ([]model.Tenant, error)
+	FindCodesByUserLogin(login string) ([]string, error)
+}
@@ -9,5 +9,6 @@ type UserServiceInterface interface {
 	Update(user model.User) error
 	FindByCriteria(criteria model.UserFilterCriteria) (model.UserSearchResult, error)
 	FindByCode(tenantId int64, orgId int64, externalId string) (model.User, error)
-	Delete(externalId string) error
+	Delete(tenantId int64, externalId string, version int64) error
+	Restore(tenantId int64, orgId int64, externalId string) error
 }
@@ -0,0 +1,48 @@
+package impl
+
+import (
+	"micro-fiber-test/pkg/model"
+	daoApi "micro-fiber-test/pkg/repository/api"
+	svcApi "micro-fiber-test/pkg/service/api"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AdminService struct {
+	orgDao  daoApi.OrgDaoInterface
+	sectDao daoApi.SectorDaoInterface
+	userDao daoApi.UserDaoInterface
+	inTx    txRunner
+}
+
+func NewAdminService(pool *pgxpool.Pool, orgDao daoApi.OrgDaoInterface, sectorDao daoApi.SectorDaoInterface, userDao daoApi.UserDaoInterface) svcApi.AdminServiceInterface {
+	return &AdminService{orgDao: orgDao, sectDao: sectorDao, userDao: userDao, inTx: poolTxRunner(pool)}
+}
+
+// Purge removes rows soft deleted more than retentionDays ago.
+// Users and sectors are purged before organizations so that foreign keys are released first.
+func (adminSvc *AdminService) Purge(retentionDays int) (model.PurgeReport, error) {
+	report := model.PurgeReport{}
+	deletedBefore := time.Now().AddDate(0, 0, -retentionDays)
+	errTx := adminSvc.inTx(func(tx pgx.Tx) error {
+		nbUsers, errUsers := adminSvc.userDao.PurgeInTx(tx, deletedBefore)
+		if errUsers != nil {
+			return errUsers
+		}
+		nbSectors, errSectors := adminSvc.sectDao.PurgeInTx(tx, deletedBefore)
+		if errSectors != nil {
+			return errSectors
+		}
+		nbOrgs, errOrgs := adminSvc.orgDao.PurgeInTx(tx, deletedBefore)
+		if errOrgs != nil {
+			return errOrgs
+		}
+		report.Users = nbUsers
+		report.Sectors = nbSectors
+		report.Organizations = nbOrgs
+		return nil
+	})
+	return report, errTx
+}
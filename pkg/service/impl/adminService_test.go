@@ -0,0 +1,32 @@
+package impl
+
+import (
+	"database/sql"
+	"micro-fiber-test/pkg/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminPurgeOrder(t *testing.T) {
+	db := &memDb{}
+	longAgo := sql.NullTime{Time: time.Now().AddDate(0, 0, -40), Valid: true}
+	recently := sql.NullTime{Time: time.Now().AddDate(0, 0, -2), Valid: true}
+	db.addOrg(1, model.OrgStatusActive).DeletedAt = longAgo
+	db.addSector(10, 1, 0, "Root", model.SectorStatusActive).DeletedAt = longAgo
+	db.addUser(100, 1, model.UserStatusActive, 10).DeletedAt = longAgo
+	// Rows deleted within the retention window are kept, and so is their organization
+	db.addOrg(2, model.OrgStatusActive).DeletedAt = longAgo
+	db.addSector(20, 2, 0, "Root", model.SectorStatusActive).DeletedAt = recently
+
+	adminSvc := AdminService{orgDao: memOrgDao{db: db}, sectDao: memSectorDao{db: db}, userDao: memUserDao{db: db}, inTx: noTx}
+	report, err := adminSvc.Purge(30)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"users.purge", "sectors.purge", "orgs.purge"}, db.calls)
+	assert.Equal(t, model.PurgeReport{Users: 1, Sectors: 1, Organizations: 1}, report)
+	assert.Nil(t, db.org(1))
+	assert.NotNil(t, db.org(2))
+	assert.NotNil(t, db.sector(20))
+	assert.Empty(t, db.members)
+}
@@ -0,0 +1,595 @@
+package impl
+
+import (
+	"database/sql"
+	"micro-fiber-test/pkg/model"
+	daoApi "micro-fiber-test/pkg/repository/api"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// memDb is an in memory copy of the tables used by the services, its DAOs mirror the SQL statements of sql_queries.toml.
+// DAO methods not needed by the tests are left to the embedded nil interfaces.
+type memDb struct {
+	orgs    []*model.Organization
+	sectors []*memSector
+	users   []*memUser
+	members []memMember
+	schemas map[int64][]model.SectorAttributeDef
+	calls   []string
+}
+
+// memSector keeps the status of a sector before its deletion, as the status_before_delete column does
+type memSector struct {
+	model.Sector
+	statusBeforeDelete model.SectorStatus
+}
+
+type memUser struct {
+	model.User
+	statusBeforeDelete model.UserStatus
+}
+
+type memMember struct {
+	userId   int64
+	sectorId int64
+}
+
+func noTx(fn func(tx pgx.Tx) error) error {
+	return fn(nil)
+}
+
+func (db *memDb) addOrg(id int64, status model.OrganizationStatus) *model.Organization {
+	org := &model.Organization{Id: id, TenantId: 1, Code: "org-" + strconv.FormatInt(id, 10), Label: "Org " + strconv.FormatInt(id, 10), Status: status, Version: 1}
+	db.orgs = append(db.orgs, org)
+	return org
+}
+
+// addSector creates a sector of the organization under parentId (0 for a root), last among its siblings
+func (db *memDb) addSector(id int64, orgId int64, parentId int64, label string, status model.SectorStatus) *memSector {
+	sector := &memSector{Sector: model.Sector{Id: id, TenantId: 1, OrgId: orgId, Code: "s" + strconv.FormatInt(id, 10), Label: label, Status: status, Version: 1,
+		Path: "/" + strconv.FormatInt(id, 10) + "/", Attributes: map[string]any{}}}
+	if parentId > 0 {
+		parent := db.sector(parentId)
+		sector.ParentId = sql.NullInt64{Int64: parentId, Valid: true}
+		sector.HasParent = true
+		sector.Depth = parent.Depth + 1
+		sector.Path = parent.Path + strconv.FormatInt(id, 10) + "/"
+		sector.Position = len(db.liveChildren(parentId))
+	}
+	db.sectors = append(db.sectors, sector)
+	return sector
+}
+
+func (db *memDb) addUser(id int64, orgId int64, status model.UserStatus, sectorIds ...int64) *memUser {
+	user := &memUser{User: model.User{Id: id, TenantId: 1, OrgId: orgId, ExternalId: "u" + strconv.FormatInt(id, 10), Login: "user" + strconv.FormatInt(id, 10), Status: status, Version: 1}}
+	db.users = append(db.users, user)
+	for _, sectorId := range sectorIds {
+		db.members = append(db.members, memMember{userId: id, sectorId: sectorId})
+	}
+	return user
+}
+
+func (db *memDb) org(id int64) *model.Organization {
+	for _, org := range db.orgs {
+		if org.Id == id {
+			return org
+		}
+	}
+	return nil
+}
+
+func (db *memDb) sector(id int64) *memSector {
+	for _, sector := range db.sectors {
+		if sector.Id == id {
+			return sector
+		}
+	}
+	return nil
+}
+
+func (db *memDb) user(id int64) *memUser {
+	for _, user := range db.users {
+		if user.Id == id {
+			return user
+		}
+	}
+	return nil
+}
+
+// liveChildren returns the non deleted children of parentId ordered by position and label
+func (db *memDb) liveChildren(parentId int64) []*memSector {
+	var children []*memSector
+	for _, sector := range db.sectors {
+		if sector.ParentId.Int64 == parentId && sector.HasParent && !sector.DeletedAt.Valid {
+			children = append(children, sector)
+		}
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		if children[i].Position != children[j].Position {
+			return children[i].Position < children[j].Position
+		}
+		return children[i].Label < children[j].Label
+	})
+	return children
+}
+
+// childLabels returns the labels of the live children of parentId in position order
+func (db *memDb) childLabels(parentId int64) []string {
+	var labels []string
+	for _, child := range db.liveChildren(parentId) {
+		labels = append(labels, child.Label)
+	}
+	return labels
+}
+
+// sectorMembers returns the ids of the users assigned to the sector
+func (db *memDb) sectorMembers(sectorId int64) []int64 {
+	var userIds []int64
+	for _, member := range db.members {
+		if member.sectorId == sectorId {
+			userIds = append(userIds, member.userId)
+		}
+	}
+	sort.Slice(userIds, func(i, j int) bool { return userIds[i] < userIds[j] })
+	return userIds
+}
+
+type memOrgDao struct {
+	daoApi.OrgDaoInterface
+	db *memDb
+}
+
+func (d memOrgDao) ExistsByCode(tenantId int64, code string) (bool, error) {
+	_, err := d.FindByCode(tenantId, code)
+	return err == nil, nil
+}
+
+func (d memOrgDao) FindByCode(tenantId int64, code string) (model.Organization, error) {
+	for _, org := range d.db.orgs {
+		if org.Code == code && !org.DeletedAt.Valid {
+			return *org, nil
+		}
+	}
+	return model.Organization{}, pgx.ErrNoRows
+}
+
+func (d memOrgDao) FindDeletedByCode(tenantId int64, code string) (model.Organization, error) {
+	for _, org := range d.db.orgs {
+		if org.Code == code && org.DeletedAt.Valid {
+			return *org, nil
+		}
+	}
+	return model.Organization{}, pgx.ErrNoRows
+}
+
+func (d memOrgDao) CountChildrenInTx(tx pgx.Tx, orgId int64) (int64, error) {
+	var cnt int64
+	for _, org := range d.db.orgs {
+		if org.ParentId.Int64 == orgId && !org.DeletedAt.Valid {
+			cnt++
+		}
+	}
+	return cnt, nil
+}
+
+func (d memOrgDao) DeleteInTx(tx pgx.Tx, tenantId int64, orgId int64, deletedAt time.Time, version int64) (int64, error) {
+	org := d.db.org(orgId)
+	if org == nil || org.DeletedAt.Valid || (version != 0 && version != org.Version) {
+		return 0, nil
+	}
+	org.Status = model.OrgStatusDeleted
+	org.DeletedAt = sql.NullTime{Time: deletedAt, Valid: true}
+	org.Version++
+	return 1, nil
+}
+
+func (d memOrgDao) RestoreInTx(tx pgx.Tx, tenantId int64, orgId int64, status model.OrganizationStatus) error {
+	org := d.db.org(orgId)
+	org.Status = status
+	org.DeletedAt = sql.NullTime{}
+	org.Version++
+	return nil
+}
+
+func (d memOrgDao) PurgeInTx(tx pgx.Tx, deletedBefore time.Time) (int64, error) {
+	d.db.calls = append(d.db.calls, "orgs.purge")
+	var kept []*model.Organization
+	var cnt int64
+	for _, org := range d.db.orgs {
+		if org.DeletedAt.Valid && org.DeletedAt.Time.Before(deletedBefore) && !d.referenced(org.Id) {
+			cnt++
+			continue
+		}
+		kept = append(kept, org)
+	}
+	d.db.orgs = kept
+	return cnt, nil
+}
+
+// referenced tells if sectors, users or child organizations still point to the organization, the purge statement skips them
+func (d memOrgDao) referenced(orgId int64) bool {
+	for _, sector := range d.db.sectors {
+		if sector.OrgId == orgId {
+			return true
+		}
+	}
+	for _, user := range d.db.users {
+		if user.OrgId == orgId {
+			return true
+		}
+	}
+	for _, org := range d.db.orgs {
+		if org.ParentId.Int64 == orgId {
+			return true
+		}
+	}
+	return false
+}
+
+type memUserDao struct {
+	daoApi.UserDaoInterface
+	db *memDb
+}
+
+func (d memUserDao) FindIdByExternalId(tenantId int64, externalId string) (int64, error) {
+	for _, user := range d.db.users {
+		if user.ExternalId == externalId && !user.DeletedAt.Valid {
+			return user.Id, nil
+		}
+	}
+	return 0, pgx.ErrNoRows
+}
+
+func (d memUserDao) CountByOrgIdInTx(tx pgx.Tx, orgId int64) (int64, error) {
+	var cnt int64
+	for _, user := range d.db.users {
+		if user.OrgId == orgId && !user.DeletedAt.Valid {
+			cnt++
+		}
+	}
+	return cnt, nil
+}
+
+func (d memUserDao) DeleteByOrgIdInTx(tx pgx.Tx, orgId int64, deletedAt time.Time) error {
+	for _, user := range d.db.users {
+		if user.OrgId == orgId && !user.DeletedAt.Valid {
+			user.statusBeforeDelete = user.Status
+			user.Status = model.UserStatusDeleted
+			user.DeletedAt = sql.NullTime{Time: deletedAt, Valid: true}
+			user.Version++
+		}
+	}
+	return nil
+}
+
+func (d memUserDao) RestoreByOrgIdInTx(tx pgx.Tx, orgId int64, deletedAt time.Time) error {
+	for _, user := range d.db.users {
+		if user.OrgId == orgId && user.DeletedAt.Valid && user.DeletedAt.Time.Equal(deletedAt) {
+			user.Status = user.statusBeforeDelete
+			user.DeletedAt = sql.NullTime{}
+			user.Version++
+		}
+	}
+	return nil
+}
+
+func (d memUserDao) MoveToOrgInTx(tx pgx.Tx, fromOrgId int64, toOrgId int64) error {
+	for _, user := range d.db.users {
+		if user.OrgId == fromOrgId && !user.DeletedAt.Valid {
+			user.OrgId = toOrgId
+			user.Version++
+		}
+	}
+	return nil
+}
+
+func (d memUserDao) PurgeInTx(tx pgx.Tx, deletedBefore time.Time) (int64, error) {
+	d.db.calls = append(d.db.calls, "users.purge")
+	var kept []*memUser
+	var cnt int64
+	for _, user := range d.db.users {
+		if user.DeletedAt.Valid && user.DeletedAt.Time.Before(deletedBefore) {
+			cnt++
+			d.db.removeMembers(func(member memMember) bool { return member.userId == user.Id })
+			continue
+		}
+		kept = append(kept, user)
+	}
+	d.db.users = kept
+	return cnt, nil
+}
+
+// removeMembers drops the memberships matching the predicate, as the users_sectors foreign keys cascade do
+func (db *memDb) removeMembers(matches func(member memMember) bool) {
+	var kept []memMember
+	for _, member := range db.members {
+		if !matches(member) {
+			kept = append(kept, member)
+		}
+	}
+	db.members = kept
+}
+
+type memSectorDao struct {
+	daoApi.SectorDaoInterface
+	db *memDb
+}
+
+func (d memSectorDao) CountByOrgIdInTx(tx pgx.Tx, orgId int64) (int64, error) {
+	var cnt int64
+	for _, sector := range d.db.sectors {
+		if sector.OrgId == orgId && !sector.DeletedAt.Valid {
+			cnt++
+		}
+	}
+	return cnt, nil
+}
+
+func (d memSectorDao) DeleteByOrgIdInTx(tx pgx.Tx, orgId int64, deletedAt time.Time) error {
+	for _, sector := range d.db.sectors {
+		if sector.OrgId == orgId && !sector.DeletedAt.Valid {
+			sector.markDeleted(deletedAt)
+		}
+	}
+	return nil
+}
+
+func (d memSectorDao) RestoreByOrgIdInTx(tx pgx.Tx, orgId int64, deletedAt time.Time) error {
+	for _, sector := range d.db.sectors {
+		if sector.OrgId == orgId && sector.DeletedAt.Valid && sector.DeletedAt.Time.Equal(deletedAt) {
+			sector.markRestored()
+		}
+	}
+	return nil
+}
+
+func (d memSectorDao) PurgeInTx(tx pgx.Tx, deletedBefore time.Time) (int64, error) {
+	d.db.calls = append(d.db.calls, "sectors.purge")
+	var kept []*memSector
+	var cnt int64
+	for _, sector := range d.db.sectors {
+		if sector.DeletedAt.Valid && sector.DeletedAt.Time.Before(deletedBefore) {
+			cnt++
+			d.db.removeMembers(func(member memMember) bool { return member.sectorId == sector.Id })
+			continue
+		}
+		kept = append(kept, sector)
+	}
+	d.db.sectors = kept
+	return cnt, nil
+}
+
+func (d memSectorDao) DeleteOrgMembershipsInTx(tx pgx.Tx, orgId int64) error {
+	d.db.removeMembers(func(member memMember) bool {
+		sector, user := d.db.sector(member.sectorId), d.db.user(member.userId)
+		return sector.OrgId == orgId && user.OrgId == orgId && !user.DeletedAt.Valid
+	})
+	return nil
+}
+
+func (d memSectorDao) FindDeletedByCode(defaultTenantId int64, code string) (model.Sector, error) {
+	for _, sector := range d.db.sectors {
+		if sector.Code == code && sector.DeletedAt.Valid {
+			return sector.Sector, nil
+		}
+	}
+	return model.Sector{}, pgx.ErrNoRows
+}
+
+func (d memSectorDao) ExistsById(defaultTenantId int64, sectorId int64) (bool, error) {
+	sector := d.db.sector(sectorId)
+	return sector != nil && !sector.DeletedAt.Valid, nil
+}
+
+func (d memSectorDao) CountMembersInTx(tx pgx.Tx, sectorId int64) (int64, error) {
+	return int64(len(d.db.sectorMembers(sectorId))), nil
+}
+
+func (d memSectorDao) CountSubtreeMembersInTx(tx pgx.Tx, sectorId int64) (int64, error) {
+	root := d.db.sector(sectorId)
+	var cnt int64
+	for _, sector := range d.db.sectors {
+		if !sector.DeletedAt.Valid && strings.HasPrefix(sector.Path, root.Path) {
+			cnt += int64(len(d.db.sectorMembers(sector.Id)))
+		}
+	}
+	return cnt, nil
+}
+
+func (d memSectorDao) DeleteInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, deletedAt time.Time, version int64) (int64, error) {
+	sector := d.db.sector(sectorId)
+	if sector == nil || sector.DeletedAt.Valid || (version != 0 && version != sector.Version) {
+		return 0, nil
+	}
+	sector.markDeleted(deletedAt)
+	return 1, nil
+}
+
+func (d memSectorDao) DeleteSubtreeInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, deletedAt time.Time, version int64) (int64, error) {
+	root := d.db.sector(sectorId)
+	if root == nil || root.DeletedAt.Valid || (version != 0 && version != root.Version) {
+		return 0, nil
+	}
+	rootPath := root.Path
+	var cnt int64
+	for _, sector := range d.db.sectors {
+		if !sector.DeletedAt.Valid && strings.HasPrefix(sector.Path, rootPath) {
+			sector.markDeleted(deletedAt)
+			cnt++
+		}
+	}
+	return cnt, nil
+}
+
+func (d memSectorDao) RestoreSectorInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, deletedAt time.Time) error {
+	root := d.db.sector(sectorId)
+	for _, sector := range d.db.sectors {
+		if sector.DeletedAt.Valid && sector.DeletedAt.Time.Equal(deletedAt) && strings.HasPrefix(sector.Path, root.Path) {
+			sector.markRestored()
+		}
+	}
+	return nil
+}
+
+func (d memSectorDao) CompactPositionsInTx(tx pgx.Tx, parentId int64) error {
+	for position, child := range d.db.liveChildren(parentId) {
+		child.Position = position
+	}
+	return nil
+}
+
+func (s *memSector) markDeleted(deletedAt time.Time) {
+	s.statusBeforeDelete = s.Status
+	s.Status = model.SectorStatusDeleted
+	s.DeletedAt = sql.NullTime{Time: deletedAt, Valid: true}
+	s.Version++
+}
+
+func (s *memSector) markRestored() {
+	s.Status = s.statusBeforeDelete
+	s.DeletedAt = sql.NullTime{}
+	s.Version++
+}
+
+func (d memSectorDao) FindByCode(defaultTenantId int64, code string) (model.Sector, error) {
+	for _, sector := range d.db.sectors {
+		if sector.Code == code && !sector.DeletedAt.Valid {
+			return sector.Sector, nil
+		}
+	}
+	return model.Sector{}, pgx.ErrNoRows
+}
+
+func (d memSectorDao) IsDescendantOf(defaultTenantId int64, sectorId int64, ancestorId int64) (bool, error) {
+	sector, ancestor := d.db.sector(sectorId), d.db.sector(ancestorId)
+	return strings.HasPrefix(sector.Path, ancestor.Path), nil
+}
+
+func (d memSectorDao) MoveInTx(tx pgx.Tx, defaultTenantId int64, sectorId int64, parentId int64, version int64) (int64, error) {
+	sector := d.db.sector(sectorId)
+	if sector == nil || sector.DeletedAt.Valid || (version != 0 && version != sector.Version) {
+		return 0, nil
+	}
+	sector.Position = d.db.nextPosition(parentId)
+	sector.ParentId = sql.NullInt64{Int64: parentId, Valid: true}
+	sector.Version++
+	return 1, nil
+}
+
+func (d memSectorDao) ReparentChildrenInTx(tx pgx.Tx, sectorId int64, parentId int64) ([]int64, error) {
+	offset := d.db.nextPosition(parentId)
+	var childIds []int64
+	for _, child := range d.db.liveChildren(sectorId) {
+		child.ParentId = sql.NullInt64{Int64: parentId, Valid: true}
+		child.Position += offset
+		child.Version++
+		childIds = append(childIds, child.Id)
+	}
+	return childIds, nil
+}
+
+func (d memSectorDao) RebaseSubtreeInTx(tx pgx.Tx, sectorId int64, parentId int64) error {
+	sector, parent := d.db.sector(sectorId), d.db.sector(parentId)
+	oldPath, oldDepth := sector.Path, sector.Depth
+	idLen := len(strconv.FormatInt(sectorId, 10))
+	for _, descendant := range d.db.sectors {
+		if strings.HasPrefix(descendant.Path, oldPath) {
+			descendant.Path = parent.Path + descendant.Path[len(oldPath)-idLen-1:]
+			descendant.Depth = descendant.Depth - oldDepth + parent.Depth + 1
+		}
+	}
+	return nil
+}
+
+// nextPosition returns the position following the live children of parentId
+func (db *memDb) nextPosition(parentId int64) int {
+	next := 0
+	for _, child := range db.liveChildren(parentId) {
+		if child.Position >= next {
+			next = child.Position + 1
+		}
+	}
+	return next
+}
+
+func (d memSectorDao) FindChildren(defaultTenantId int64, parentId int64) ([]model.Sector, error) {
+	var children []model.Sector
+	for _, child := range d.db.liveChildren(parentId) {
+		children = append(children, child.Sector)
+	}
+	return children, nil
+}
+
+func (d memSectorDao) ShiftPositionsInTx(tx pgx.Tx, parentId int64, fromPosition int) error {
+	for _, child := range d.db.liveChildren(parentId) {
+		if child.Position >= fromPosition {
+			child.Position++
+		}
+	}
+	return nil
+}
+
+// CreateInTx gives the sector the next free id and computes its path as the sectors trigger does
+func (d memSectorDao) CreateInTx(tx pgx.Tx, sector model.Sector) (int64, error) {
+	for _, existing := range d.db.sectors {
+		if existing.Id >= sector.Id {
+			sector.Id = existing.Id + 1
+		}
+	}
+	sector.Path = "/" + strconv.FormatInt(sector.Id, 10) + "/"
+	if sector.HasParent {
+		sector.Path = d.db.sector(sector.ParentId.Int64).Path + strconv.FormatInt(sector.Id, 10) + "/"
+	}
+	sector.Version = 1
+	d.db.sectors = append(d.db.sectors, &memSector{Sector: sector})
+	return sector.Id, nil
+}
+
+func (d memSectorDao) UpdateAttributesInTx(tx pgx.Tx, sectorId int64, attributes map[string]any) error {
+	sector := d.db.sector(sectorId)
+	sector.Attributes = attributes
+	sector.Version++
+	return nil
+}
+
+// MoveMembersInTx keeps a single assignment for the users already assigned to the target, as "on conflict do nothing" does
+func (d memSectorDao) MoveMembersInTx(tx pgx.Tx, fromSectorId int64, toSectorId int64) error {
+	moved := d.db.sectorMembers(fromSectorId)
+	existing := d.db.sectorMembers(toSectorId)
+	d.db.removeMembers(func(member memMember) bool { return member.sectorId == fromSectorId })
+	for _, userId := range moved {
+		if !slices.Contains(existing, userId) {
+			d.db.members = append(d.db.members, memMember{userId: userId, sectorId: toSectorId})
+		}
+	}
+	return nil
+}
+
+func (d memSectorDao) MoveMemberInTx(tx pgx.Tx, userId int64, fromSectorId int64, toSectorId int64) (int64, error) {
+	for inc, member := range d.db.members {
+		if member.userId == userId && member.sectorId == fromSectorId {
+			d.db.members[inc].sectorId = toSectorId
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+type memSchemaDao struct {
+	daoApi.SectorSchemaDaoInterface
+	db *memDb
+}
+
+func (d memSchemaDao) FindByOrgId(tenantId int64, orgId int64) (model.SectorAttributeSchema, error) {
+	defs, found := d.db.schemas[orgId]
+	if !found {
+		return model.SectorAttributeSchema{}, pgx.ErrNoRows
+	}
+	return model.SectorAttributeSchema{OrgId: orgId, TenantId: tenantId, Attributes: defs}, nil
+}
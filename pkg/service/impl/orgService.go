@@ -2,24 +2,31 @@ package impl
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/helpers"
 	"micro-fiber-test/pkg/model"
 	daoApi "micro-fiber-test/pkg/repository/api"
 	svcApi "micro-fiber-test/pkg/service/api"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type OrganizationService struct {
-	orgDao  daoApi.OrgDaoInterface
-	sectDao daoApi.SectorDaoInterface
-	dbPool  *pgxpool.Pool
+	orgDao      daoApi.OrgDaoInterface
+	sectDao     daoApi.SectorDaoInterface
+	userDao     daoApi.UserDaoInterface
+	templateDao daoApi.TemplateDaoInterface
+	schemaDao   daoApi.SectorSchemaDaoInterface
+	inTx        txRunner
 }
 
-func (orgService *OrganizationService) Create(cnxParams string, defaultTenant int64, organization model.Organization) (int64, error) {
+func (orgService *OrganizationService) Create(cnxParams string, defaultTenant int64, organization model.Organization, templateCode string) (int64, error) {
 	orgExists, err := orgService.orgDao.ExistsByCode(defaultTenant, organization.Code)
 	if err != nil {
 		return 0, err
@@ -36,6 +43,24 @@ func (orgService *OrganizationService) Create(cnxParams string, defaultTenant in
 		return 0, errors.New(commons.OrgAlreadyExistsByLabel)
 	}
 
+	parentId, errParent := orgService.resolveParent(defaultTenant, organization, organization.ParentCode.String)
+	if errParent != nil {
+		return 0, errParent
+	}
+	organization.ParentId = parentId
+
+	var templateNodes []model.SectorTemplateNode
+	if templateCode != "" {
+		template, errTemplate := orgService.templateDao.FindByCode(defaultTenant, templateCode)
+		if errors.Is(errTemplate, pgx.ErrNoRows) {
+			return 0, errors.New(commons.TemplateNotFound)
+		}
+		if errTemplate != nil {
+			return 0, errTemplate
+		}
+		templateNodes = template.Nodes
+	}
+
 	conn, errConnect := pgx.Connect(context.Background(), cnxParams)
 	if errConnect != nil {
 		return -1, errConnect
@@ -70,6 +95,7 @@ func (orgService *OrganizationService) Create(cnxParams string, defaultTenant in
 
 	id, errOrgCreateTx := orgService.orgDao.CreateInTx(tx, organization)
 	if errOrgCreateTx != nil {
+		errTx = errOrgCreateTx
 		return 0, errOrgCreateTx
 	}
 	sector := model.Sector{}
@@ -80,14 +106,152 @@ func (orgService *OrganizationService) Create(cnxParams string, defaultTenant in
 	sector.Depth = 0
 	sector.HasParent = false
 	sector.OrgId = id
-	_, errSect := orgService.sectDao.CreateInTx(tx, sector)
+	rootId, errSect := orgService.sectDao.CreateInTx(tx, sector)
 	if errSect != nil {
+		errTx = errSect
 		return 0, errSect
 	}
+	sector.Id = rootId
+	errTemplate := orgService.createTemplateSectors(tx, sector, templateNodes)
+	if errTemplate != nil {
+		errTx = errTemplate
+		return 0, errTemplate
+	}
 	return id, nil
 }
 
-func (orgService *OrganizationService) Update(defaultTenant int64, orgCode string, label string) error {
+// createTemplateSectors creates the template nodes, and recursively their children, under the parent sector.
+// Node attributes are copied as is, a new organization has no attribute schema to check them against.
+func (orgService *OrganizationService) createTemplateSectors(tx pgx.Tx, parent model.Sector, nodes []model.SectorTemplateNode) error {
+	for pos, node := range nodes {
+		sector := model.Sector{
+			TenantId:   parent.TenantId,
+			OrgId:      parent.OrgId,
+			Code:       uuid.New().String(),
+			Label:      node.Label,
+			ParentId:   sql.NullInt64{Int64: parent.Id, Valid: true},
+			HasParent:  true,
+			Depth:      parent.Depth + 1,
+			Position:   pos,
+			Status:     model.SectorStatusActive,
+			Attributes: node.Attributes,
+		}
+		id, errCreate := orgService.sectDao.CreateInTx(tx, sector)
+		if errCreate != nil {
+			return errCreate
+		}
+		sector.Id = id
+		errChildren := orgService.createTemplateSectors(tx, sector, node.Children)
+		if errChildren != nil {
+			return errChildren
+		}
+	}
+	return nil
+}
+
+// Clone creates a draft copy of the organization with a deep copy of its sector tree and its sector attribute schema,
+// every copied sector gets a new code and keeps its attributes.
+// Users are not cloned, memberships cannot be copied since they would assign users of the source organization to the clone.
+func (orgService *OrganizationService) Clone(defaultTenant int64, orgCode string, options model.OrgCloneOptions) (string, error) {
+	if options.CopyMembers {
+		return "", errors.New(commons.OrgCloneMembersInvalid)
+	}
+	org, errFind := orgService.FindByCode(defaultTenant, orgCode)
+	if errFind != nil {
+		return "", errFind
+	}
+	exists, errLabel := orgService.orgDao.ExistsByLabel(defaultTenant, options.Label)
+	if errLabel != nil {
+		return "", errLabel
+	}
+	if exists {
+		return "", errors.New(commons.OrgAlreadyExistsByLabel)
+	}
+	sectors, errSectors := orgService.sectDao.FindSectorsByTenantOrg(defaultTenant, org.Id)
+	if errSectors != nil {
+		return "", errSectors
+	}
+
+	clone := model.Organization{
+		TenantId: defaultTenant,
+		Code:     uuid.New().String(),
+		Label:    options.Label,
+		Type:     org.Type,
+		Status:   model.OrgStatusDraft,
+		ParentId: org.ParentId,
+	}
+	errTx := orgService.inTx(func(tx pgx.Tx) error {
+		cloneId, errCreate := orgService.orgDao.CreateInTx(tx, clone)
+		if errCreate != nil {
+			return errCreate
+		}
+		clone.Id = cloneId
+		errSectors := orgService.copySectors(tx, clone, sectors)
+		if errSectors != nil {
+			return errSectors
+		}
+		return orgService.schemaDao.CopyInTx(tx, org.Id, clone.Id)
+	})
+	if errTx != nil {
+		return "", errTx
+	}
+	return clone.Code, nil
+}
+
+// copySectors copies the sectors into the target organization, parents first.
+// The root sector takes the code and label of the target organization, like in Create.
+func (orgService *OrganizationService) copySectors(tx pgx.Tx, target model.Organization, sectors []model.Sector) error {
+	children := make(map[int64][]model.Sector)
+	var roots []model.Sector
+	for _, sector := range sectors {
+		if sector.HasParent && sector.ParentId.Valid {
+			children[sector.ParentId.Int64] = append(children[sector.ParentId.Int64], sector)
+		} else {
+			roots = append(roots, sector)
+		}
+	}
+
+	var copySubTree func(source model.Sector, parentId sql.NullInt64, depth int) error
+	copySubTree = func(source model.Sector, parentId sql.NullInt64, depth int) error {
+		sector := model.Sector{
+			TenantId:   target.TenantId,
+			OrgId:      target.Id,
+			Code:       uuid.New().String(),
+			Label:      source.Label,
+			ParentId:   parentId,
+			HasParent:  parentId.Valid,
+			Depth:      depth,
+			Position:   source.Position,
+			Status:     source.Status,
+			Attributes: source.Attributes,
+		}
+		if !parentId.Valid {
+			sector.Code = target.Code
+			sector.Label = target.Label
+		}
+		id, errCreate := orgService.sectDao.CreateInTx(tx, sector)
+		if errCreate != nil {
+			return errCreate
+		}
+		for _, child := range children[source.Id] {
+			errChild := copySubTree(child, sql.NullInt64{Int64: id, Valid: true}, depth+1)
+			if errChild != nil {
+				return errChild
+			}
+		}
+		return nil
+	}
+
+	for _, root := range roots {
+		errRoot := copySubTree(root, sql.NullInt64{}, 0)
+		if errRoot != nil {
+			return errRoot
+		}
+	}
+	return nil
+}
+
+func (orgService *OrganizationService) Update(defaultTenant int64, orgCode string, label string, version int64) error {
 	orgExists, err := orgService.orgDao.ExistsByCode(defaultTenant, orgCode)
 	if err != nil {
 		return err
@@ -95,26 +259,242 @@ func (orgService *OrganizationService) Update(defaultTenant int64, orgCode strin
 	if !orgExists {
 		return errors.New(commons.OrgDoesNotExistByCode)
 	}
-	return orgService.orgDao.Update(orgCode, label)
+	org, errFind := orgService.orgDao.FindByCode(defaultTenant, orgCode)
+	if errFind != nil {
+		return errFind
+	}
+	errVersion := checkVersion(version, org.Version)
+	if errVersion != nil {
+		return errVersion
+	}
+	errLabel := orgService.ensureLabelAvailable(defaultTenant, org.Id, label)
+	if errLabel != nil {
+		return errLabel
+	}
+	return checkUpdated(orgService.orgDao.Update(defaultTenant, orgCode, label, version))
 }
 
-func (orgService *OrganizationService) Delete(defaultTenant int64, orgCode string) error {
-	orgExists, errExists := orgService.orgDao.ExistsByCode(defaultTenant, orgCode)
-	if errExists != nil {
-		return errExists
+func (orgService *OrganizationService) Patch(defaultTenant int64, orgCode string, patch model.OrganizationPatch, version int64) error {
+	org, errFind := orgService.FindByCode(defaultTenant, orgCode)
+	if errFind != nil {
+		return errFind
 	}
-	if !orgExists {
-		return errors.New(commons.OrgDoesNotExistByCode)
+	errVersion := checkVersion(version, org.Version)
+	if errVersion != nil {
+		return errVersion
+	}
+	if patch.Type != nil {
+		if !helpers.IsOrgTypeValid(*patch.Type) {
+			return errors.New(commons.OrgInvalidType)
+		}
+		org.Type = *patch.Type
+	}
+	if patch.Status != nil && *patch.Status != org.Status {
+		if !helpers.IsOrgTransitionAllowed(org.Status, *patch.Status) {
+			return errors.New(commons.OrgIllegalTransition)
+		}
+		org.Status = *patch.Status
+	}
+	if patch.Label != nil && *patch.Label != org.Label {
+		errLabel := orgService.ensureLabelAvailable(defaultTenant, org.Id, *patch.Label)
+		if errLabel != nil {
+			return errLabel
+		}
+		org.Label = *patch.Label
+	}
+	if patch.ParentCode != nil || patch.Type != nil {
+		parentCode := org.ParentCode.String
+		if patch.ParentCode != nil {
+			parentCode = *patch.ParentCode
+		}
+		parentId, errParent := orgService.resolveParent(defaultTenant, org, parentCode)
+		if errParent != nil {
+			return errParent
+		}
+		org.ParentId = parentId
+	}
+	if patch.Type != nil {
+		children, errChildren := orgService.orgDao.FindChildren(defaultTenant, org.Id)
+		if errChildren != nil {
+			return errChildren
+		}
+		for _, child := range children {
+			if !helpers.IsOrgParentTypeAllowed(child.Type, org.Type) {
+				return errors.New(commons.OrgParentTypeInvalid)
+			}
+		}
+	}
+	return checkUpdated(orgService.orgDao.Patch(org, version))
+}
+
+// resolveParent checks that the parent organization exists, accepts the organization type
+// and is not the organization itself or one of its descendants. An empty code means no parent.
+func (orgService *OrganizationService) resolveParent(defaultTenant int64, org model.Organization, parentCode string) (sql.NullInt64, error) {
+	if parentCode == "" {
+		return sql.NullInt64{}, nil
+	}
+	parent, errFind := orgService.orgDao.FindByCode(defaultTenant, parentCode)
+	if errors.Is(errFind, pgx.ErrNoRows) {
+		return sql.NullInt64{}, errors.New(commons.OrgParentNotFound)
 	}
-	org, errFind := orgService.orgDao.FindByCode(orgCode)
+	if errFind != nil {
+		return sql.NullInt64{}, errFind
+	}
+	if !helpers.IsOrgParentTypeAllowed(org.Type, parent.Type) {
+		return sql.NullInt64{}, errors.New(commons.OrgParentTypeInvalid)
+	}
+	if org.Id > 0 {
+		ancestorIds, errAncestors := orgService.orgDao.FindAncestorIds(parent.Id)
+		if errAncestors != nil {
+			return sql.NullInt64{}, errAncestors
+		}
+		for _, ancestorId := range ancestorIds {
+			if ancestorId == org.Id {
+				return sql.NullInt64{}, errors.New(commons.OrgParentCycle)
+			}
+		}
+	}
+	return sql.NullInt64{Int64: parent.Id, Valid: true}, nil
+}
+
+// ensureLabelAvailable checks that no other organization of the tenant uses label
+func (orgService *OrganizationService) ensureLabelAvailable(defaultTenant int64, orgId int64, label string) error {
+	labelOrgId, errLabel := orgService.orgDao.FindIdByLabel(defaultTenant, label)
+	if errLabel != nil {
+		return errLabel
+	}
+	if labelOrgId > 0 && labelOrgId != orgId {
+		return errors.New(commons.OrgAlreadyExistsByLabel)
+	}
+	return nil
+}
+
+func (orgService *OrganizationService) ChangeStatus(defaultTenant int64, orgCode string, action model.OrgLifecycleAction) error {
+	if action == model.OrgActionRestore {
+		// Soft deleted organizations are hidden from FindByCode, look them up first
+		deletedOrg, errDeleted := orgService.orgDao.FindDeletedByCode(defaultTenant, orgCode)
+		if errDeleted == nil {
+			return orgService.restoreDeleted(deletedOrg)
+		}
+		if !errors.Is(errDeleted, pgx.ErrNoRows) {
+			return errDeleted
+		}
+	}
+	org, errFind := orgService.FindByCode(defaultTenant, orgCode)
 	if errFind != nil {
 		return errFind
 	}
-	errSector := orgService.sectDao.DeleteByOrgId(org.Id)
-	if errSector != nil {
-		return errSector
+	targetStatus, errTransition := helpers.NextOrgStatus(action, org.Status)
+	if errTransition != nil {
+		return errTransition
+	}
+	return orgService.orgDao.UpdateStatus(defaultTenant, orgCode, targetStatus)
+}
+
+func (orgService *OrganizationService) Delete(defaultTenant int64, orgCode string, options model.OrgDeleteOptions) (model.OrgDeletionReport, error) {
+	report := model.OrgDeletionReport{}
+	org, errFind := orgService.FindByCode(defaultTenant, orgCode)
+	if errFind != nil {
+		return report, errFind
+	}
+	errVersion := checkVersion(options.Version, org.Version)
+	if errVersion != nil {
+		return report, errVersion
+	}
+
+	var targetOrg model.Organization
+	switch options.Strategy {
+	case model.OrgDeleteRefuse, model.OrgDeleteCascade:
+	case model.OrgDeleteMove:
+		if options.TargetOrgCode == "" || options.TargetOrgCode == orgCode {
+			return report, errors.New(commons.OrgMoveTargetInvalid)
+		}
+		target, errTarget := orgService.FindByCode(defaultTenant, options.TargetOrgCode)
+		if errTarget != nil {
+			if errTarget.Error() == commons.OrgDoesNotExistByCode {
+				return report, errors.New(commons.OrgMoveTargetNotFound)
+			}
+			return report, errTarget
+		}
+		if !helpers.OrgAcceptsChildren(target.Status) {
+			return report, errors.New(commons.OrgNotActive)
+		}
+		targetOrg = target
+	default:
+		return report, errors.New(commons.OrgInvalidStrategy)
+	}
+
+	// Users and sectors share the organization deletion timestamp so that they can be restored with it
+	deletedAt := time.Now()
+	errTx := orgService.inTx(func(tx pgx.Tx) error {
+		nbUsers, errUsers := orgService.userDao.CountByOrgIdInTx(tx, org.Id)
+		if errUsers != nil {
+			return errUsers
+		}
+		nbSectors, errSectors := orgService.sectDao.CountByOrgIdInTx(tx, org.Id)
+		if errSectors != nil {
+			return errSectors
+		}
+		nbChildren, errChildren := orgService.orgDao.CountChildrenInTx(tx, org.Id)
+		if errChildren != nil {
+			return errChildren
+		}
+		report.Users = nbUsers
+		report.Sectors = nbSectors
+		report.Children = nbChildren
+
+		// Child organizations are never deleted along with their parent, they must be detached or deleted first
+		report.Refused = (options.Strategy == model.OrgDeleteRefuse && nbUsers > 0) || nbChildren > 0
+		if options.DryRun {
+			return nil
+		}
+		if nbChildren > 0 {
+			return errors.New(commons.OrgHasChildren)
+		}
+		if report.Refused {
+			return errors.New(commons.OrgHasUsers)
+		}
+
+		if nbUsers > 0 {
+			var errMembers error
+			if options.Strategy == model.OrgDeleteMove {
+				// Moved users cannot stay assigned to the sectors of the deleted organization
+				errMembers = orgService.sectDao.DeleteOrgMembershipsInTx(tx, org.Id)
+				if errMembers == nil {
+					errMembers = orgService.userDao.MoveToOrgInTx(tx, org.Id, targetOrg.Id)
+				}
+			} else {
+				errMembers = orgService.userDao.DeleteByOrgIdInTx(tx, org.Id, deletedAt)
+			}
+			if errMembers != nil {
+				return errMembers
+			}
+		}
+		errSector := orgService.sectDao.DeleteByOrgIdInTx(tx, org.Id, deletedAt)
+		if errSector != nil {
+			return errSector
+		}
+		return checkUpdated(orgService.orgDao.DeleteInTx(tx, defaultTenant, org.Id, deletedAt, options.Version))
+	})
+	return report, errTx
+}
+
+func (orgService *OrganizationService) restoreDeleted(org model.Organization) error {
+	targetStatus, errTransition := helpers.NextOrgStatus(model.OrgActionRestore, org.Status)
+	if errTransition != nil {
+		return errTransition
 	}
-	return orgService.orgDao.Delete(orgCode)
+	return orgService.inTx(func(tx pgx.Tx) error {
+		errUsers := orgService.userDao.RestoreByOrgIdInTx(tx, org.Id, org.DeletedAt.Time)
+		if errUsers != nil {
+			return errUsers
+		}
+		errSector := orgService.sectDao.RestoreByOrgIdInTx(tx, org.Id, org.DeletedAt.Time)
+		if errSector != nil {
+			return errSector
+		}
+		return orgService.orgDao.RestoreInTx(tx, org.TenantId, org.Id, targetStatus)
+	})
 }
 
 func (orgService *OrganizationService) FindByCode(defaultTenant int64, code string) (model.Organization, error) {
@@ -126,7 +506,15 @@ func (orgService *OrganizationService) FindByCode(defaultTenant int64, code stri
 	if !orgExists {
 		return nilOrg, errors.New(commons.OrgDoesNotExistByCode)
 	}
-	return orgService.orgDao.FindByCode(code)
+	return orgService.orgDao.FindByCode(defaultTenant, code)
+}
+
+func (orgService *OrganizationService) FindChildren(defaultTenant int64, code string) ([]model.Organization, error) {
+	org, errFind := orgService.FindByCode(defaultTenant, code)
+	if errFind != nil {
+		return nil, errFind
+	}
+	return orgService.orgDao.FindChildren(defaultTenant, org.Id)
 }
 
 func (orgService *OrganizationService) FindAll(defaultTenant int64) ([]model.Organization, error) {
@@ -136,6 +524,20 @@ func (orgService *OrganizationService) FindAll(defaultTenant int64) ([]model.Org
 	}
 	return orgs, nil
 }
-func NewOrgService(pool *pgxpool.Pool, orgDao daoApi.OrgDaoInterface, sectorDao daoApi.SectorDaoInterface) svcApi.OrganizationServiceInterface {
-	return &OrganizationService{orgDao: orgDao, sectDao: sectorDao, dbPool: pool}
+
+func (orgService *OrganizationService) FindByCriteria(criteria model.OrgFilterCriteria) (model.OrgSearchResult, error) {
+	orgSearchResult, err := orgService.orgDao.FindByCriteria(criteria)
+	if err != nil {
+		return orgSearchResult, err
+	}
+	cnt, errCount := orgService.orgDao.CountByCriteria(criteria)
+	if errCount != nil {
+		return orgSearchResult, errCount
+	}
+	orgSearchResult.NbResults = cnt
+	return orgSearchResult, nil
+}
+
+func NewOrgService(pool *pgxpool.Pool, orgDao daoApi.OrgDaoInterface, sectorDao daoApi.SectorDaoInterface, userDao daoApi.UserDaoInterface, templateDao daoApi.TemplateDaoInterface, schemaDao daoApi.SectorSchemaDaoInterface) svcApi.OrganizationServiceInterface {
+	return &OrganizationService{orgDao: orgDao, sectDao: sectorDao, userDao: userDao, templateDao: templateDao, schemaDao: schemaDao, inTx: poolTxRunner(pool)}
 }
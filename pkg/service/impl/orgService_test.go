@@ -0,0 +1,70 @@
+package impl
+
+import (
+	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMemOrgService(db *memDb) *OrganizationService {
+	return &OrganizationService{orgDao: memOrgDao{db: db}, sectDao: memSectorDao{db: db}, userDao: memUserDao{db: db}, inTx: noTx}
+}
+
+func TestOrgRestoreKeepsStatuses(t *testing.T) {
+	db := &memDb{}
+	db.addOrg(1, model.OrgStatusActive)
+	db.addSector(10, 1, 0, "Root", model.SectorStatusActive)
+	db.addSector(11, 1, 10, "Sales", model.SectorStatusInactive)
+	db.addUser(100, 1, model.UserStatusActive, 10)
+	db.addUser(101, 1, model.UserStatusInactive, 11)
+	orgSvc := newMemOrgService(db)
+
+	report, err := orgSvc.Delete(1, "org-1", model.OrgDeleteOptions{Strategy: model.OrgDeleteCascade})
+	assert.Nil(t, err)
+	assert.Equal(t, model.OrgDeletionReport{Users: 2, Sectors: 2}, report)
+	assert.Equal(t, model.SectorStatusDeleted, db.sector(11).Status)
+	assert.Equal(t, model.UserStatusDeleted, db.user(101).Status)
+
+	assert.Nil(t, orgSvc.ChangeStatus(1, "org-1", model.OrgActionRestore))
+	assert.False(t, db.org(1).DeletedAt.Valid)
+	assert.Equal(t, model.OrgStatusInactive, db.org(1).Status)
+	assert.Equal(t, model.SectorStatusActive, db.sector(10).Status)
+	assert.Equal(t, model.SectorStatusInactive, db.sector(11).Status)
+	assert.Equal(t, model.UserStatusActive, db.user(100).Status)
+	assert.Equal(t, model.UserStatusInactive, db.user(101).Status)
+	assert.False(t, db.user(101).DeletedAt.Valid)
+}
+
+func TestOrgDeleteMoveDropsMemberships(t *testing.T) {
+	db := &memDb{}
+	db.addOrg(1, model.OrgStatusActive)
+	db.addOrg(2, model.OrgStatusActive)
+	db.addSector(10, 1, 0, "Root", model.SectorStatusActive)
+	db.addSector(20, 2, 0, "Root", model.SectorStatusActive)
+	db.addUser(100, 1, model.UserStatusActive, 10)
+	db.addUser(200, 2, model.UserStatusActive, 20)
+	orgSvc := newMemOrgService(db)
+
+	report, err := orgSvc.Delete(1, "org-1", model.OrgDeleteOptions{Strategy: model.OrgDeleteMove, TargetOrgCode: "org-2"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), report.Users)
+	assert.Equal(t, int64(2), db.user(100).OrgId)
+	assert.Empty(t, db.sectorMembers(10))
+	assert.Equal(t, []int64{200}, db.sectorMembers(20))
+	assert.True(t, db.sector(10).DeletedAt.Valid)
+}
+
+func TestOrgCloneRefusesMembers(t *testing.T) {
+	db := &memDb{}
+	db.addOrg(1, model.OrgStatusActive)
+	db.addSector(10, 1, 0, "Root", model.SectorStatusActive)
+	db.addUser(100, 1, model.UserStatusActive, 10)
+	orgSvc := newMemOrgService(db)
+
+	_, err := orgSvc.Clone(1, "org-1", model.OrgCloneOptions{Label: "Copy", CopyMembers: true})
+	assert.EqualError(t, err, commons.OrgCloneMembersInvalid)
+	assert.Len(t, db.orgs, 1)
+	assert.Equal(t, []int64{100}, db.sectorMembers(10))
+}
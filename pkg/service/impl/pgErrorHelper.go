@@ -0,0 +1,19 @@
+package impl
+
+import (
+	"errors"
+	"micro-fiber-test/pkg/dto/commons"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const pgUniqueViolation = "23505"
+
+// sectorConflict turns a unique constraint violation raised by postgres into SectorAlreadyExist
+func sectorConflict(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return errors.New(commons.SectorAlreadyExist)
+	}
+	return err
+}
@@ -1,24 +1,37 @@
 package impl
 
 import (
+	"database/sql"
 	"errors"
 	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/helpers"
 	"micro-fiber-test/pkg/model"
 	"micro-fiber-test/pkg/repository/api"
 	svcApi "micro-fiber-test/pkg/service/api"
+	"micro-fiber-test/pkg/validation"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type SectorService struct {
-	dao api.SectorDaoInterface
+	dao       api.SectorDaoInterface
+	userDao   api.UserDaoInterface
+	schemaDao api.SectorSchemaDaoInterface
+	inTx      txRunner
 }
 
-func NewSectorService(daoP api.SectorDaoInterface) svcApi.SectorServiceInterface {
-	return &SectorService{dao: daoP}
+func NewSectorService(pool *pgxpool.Pool, daoP api.SectorDaoInterface, userDao api.UserDaoInterface, schemaDao api.SectorSchemaDaoInterface) svcApi.SectorServiceInterface {
+	return &SectorService{dao: daoP, userDao: userDao, schemaDao: schemaDao, inTx: poolTxRunner(pool)}
 }
 
+// Create inserts the sector among its siblings at sector.Position, model.SectorPositionLast or an out of range position appends it.
+// Labels are unique among the live children of a parent.
 func (sectorSvc SectorService) Create(defautTenantId int64, sector model.Sector) (int64, error) {
 	sector.TenantId = defautTenantId
-	id, _, err := sectorSvc.dao.FindByLabel(defautTenantId, sector.Label)
+	id, _, err := sectorSvc.dao.FindSiblingByLabel(defautTenantId, sector.OrgId, sector.ParentId.Int64, sector.Label)
 	if err != nil {
 		return 0, err
 	}
@@ -26,38 +39,781 @@ func (sectorSvc SectorService) Create(defautTenantId int64, sector model.Sector)
 		return 0, errors.New(commons.SectorAlreadyExist)
 	}
 
-	id, createErr := sectorSvc.dao.Create(sector)
-	if createErr != nil {
-		return 0, createErr
+	errTx := sectorSvc.inTx(func(tx pgx.Tx) error {
+		nbSiblings, errCount := sectorSvc.dao.CountChildrenInTx(tx, sector.ParentId.Int64)
+		if errCount != nil {
+			return errCount
+		}
+		if sector.Position < 0 || sector.Position >= nbSiblings {
+			sector.Position = nbSiblings
+		} else {
+			errShift := sectorSvc.dao.ShiftPositionsInTx(tx, sector.ParentId.Int64, sector.Position)
+			if errShift != nil {
+				return errShift
+			}
+		}
+		var errCreate error
+		id, errCreate = sectorSvc.dao.CreateInTx(tx, sector)
+		return errCreate
+	})
+	if errTx != nil {
+		return 0, sectorConflict(errTx)
+	}
+	return id, nil
+}
+
+// CreateTree creates the nodes and all their descendants under the parent sector (the root sector when parentCode is empty)
+// in one transaction, after the existing children of the parent. The nodes are returned with their generated codes.
+func (sectorSvc SectorService) CreateTree(defaultTenantId int64, orgId int64, parentCode string, nodes []model.SectorTreeNode) ([]model.SectorTreeNode, error) {
+	var parent model.Sector
+	if parentCode == "" {
+		rootId, errRoot := sectorSvc.dao.FindRootSector(defaultTenantId, orgId)
+		if errRoot != nil {
+			return nil, errRoot
+		}
+		if rootId == 0 {
+			return nil, errors.New(commons.SectorRootNotFound)
+		}
+		parent = model.Sector{Id: rootId, TenantId: defaultTenantId, OrgId: orgId}
 	} else {
-		return id, nil
+		var errParent error
+		parent, errParent = sectorSvc.dao.FindByCode(defaultTenantId, parentCode)
+		if errors.Is(errParent, pgx.ErrNoRows) || (errParent == nil && parent.OrgId != orgId) {
+			return nil, errors.New(commons.SectorParentNotFound)
+		}
+		if errParent != nil {
+			return nil, errParent
+		}
 	}
+
+	errTx := sectorSvc.inTx(func(tx pgx.Tx) error {
+		nbSiblings, errCount := sectorSvc.dao.CountChildrenInTx(tx, parent.Id)
+		if errCount != nil {
+			return errCount
+		}
+		return sectorSvc.createTreeNodes(tx, parent, nodes, nbSiblings)
+	})
+	if errTx != nil {
+		return nil, sectorConflict(errTx)
+	}
+	return nodes, nil
 }
 
-func (sectorSvc SectorService) FindSectorsByTenantOrg(defaultTenantId int64, orgId int64) ([]model.Sector, error) {
-	sectors, err := sectorSvc.dao.FindSectorsByTenantOrg(defaultTenantId, orgId)
-	if err != nil {
-		return nil, err
+// createTreeNodes creates the nodes, and recursively their children, under the parent sector starting at the given position
+func (sectorSvc SectorService) createTreeNodes(tx pgx.Tx, parent model.Sector, nodes []model.SectorTreeNode, firstPosition int) error {
+	for inc := range nodes {
+		sector := model.Sector{
+			TenantId:   parent.TenantId,
+			OrgId:      parent.OrgId,
+			Code:       uuid.New().String(),
+			Label:      nodes[inc].Label,
+			ParentId:   sql.NullInt64{Int64: parent.Id, Valid: true},
+			HasParent:  true,
+			Depth:      parent.Depth + 1,
+			Position:   firstPosition + inc,
+			Status:     nodes[inc].Status,
+			Attributes: nodes[inc].Attributes,
+		}
+		id, errCreate := sectorSvc.dao.CreateInTx(tx, sector)
+		if errCreate != nil {
+			return errCreate
+		}
+		sector.Id = id
+		nodes[inc].Code = sector.Code
+		errChildren := sectorSvc.createTreeNodes(tx, sector, nodes[inc].Children, 0)
+		if errChildren != nil {
+			return errChildren
+		}
 	}
-	return sectors, nil
+	return nil
+}
+
+// Import upserts the rows by code in one transaction once all of them are valid, otherwise a helpers.SectorImportError lists the issues.
+// Moved sectors are placed after their new siblings along with their subtree.
+func (sectorSvc SectorService) Import(defaultTenantId int64, orgId int64, rows []model.SectorImportRow) (model.SectorImportReport, error) {
+	report := model.SectorImportReport{}
+	existing, errExisting := sectorSvc.dao.FindSectorsByTenantOrg(defaultTenantId, orgId)
+	if errExisting != nil {
+		return report, errExisting
+	}
+	codes := make([]string, len(rows))
+	for inc, row := range rows {
+		codes[inc] = row.Code
+	}
+	codesInUse, errCodes := sectorSvc.dao.FindCodesInUse(defaultTenantId, orgId, codes)
+	if errCodes != nil {
+		return report, errCodes
+	}
+	var issues []model.SectorImportIssue
+	if len(codesInUse) > 0 {
+		taken := make(map[string]bool, len(codesInUse))
+		for _, code := range codesInUse {
+			taken[code] = true
+		}
+		for _, row := range rows {
+			if taken[row.Code] {
+				issues = append(issues, model.SectorImportIssue{Line: row.Line, Code: row.Code, Reason: commons.SectorImportCodeTaken})
+			}
+		}
+	}
+	actions, planIssues := helpers.PlanSectorImport(rows, existing)
+	issues = append(issues, planIssues...)
+
+	sectorsByCode := make(map[string]model.Sector, len(existing)+len(actions))
+	for _, sector := range existing {
+		sectorsByCode[sector.Code] = sector
+	}
+	// Attributes are checked as they will be stored, rows without attributes keep those of the existing sector
+	defs, errSchema := sectorSvc.FindAttributeSchema(defaultTenantId, orgId)
+	if errSchema != nil {
+		return report, errSchema
+	}
+	for _, row := range rows {
+		attributes := row.Attributes
+		if attributes == nil {
+			attributes = sectorsByCode[row.Code].Attributes
+		}
+		if len(helpers.ValidateSectorAttributes(defs, attributes)) > 0 {
+			issues = append(issues, model.SectorImportIssue{Line: row.Line, Code: row.Code, Reason: commons.SectorImportBadAttrs})
+		}
+	}
+	if len(issues) > 0 {
+		return report, helpers.SectorImportError{Issues: issues}
+	}
+
+	errTx := sectorSvc.inTx(func(tx pgx.Tx) error {
+		var previousParents []int64
+		for _, action := range actions {
+			row := action.Row
+			if !action.Exists {
+				parent := sectorsByCode[row.ParentCode]
+				nbSiblings, errCount := sectorSvc.dao.CountChildrenInTx(tx, parent.Id)
+				if errCount != nil {
+					return errCount
+				}
+				sector := model.Sector{
+					TenantId:   defaultTenantId,
+					OrgId:      orgId,
+					Code:       row.Code,
+					Label:      row.Label,
+					ParentId:   sql.NullInt64{Int64: parent.Id, Valid: true},
+					HasParent:  true,
+					Depth:      action.Depth,
+					Position:   nbSiblings,
+					Status:     row.Status,
+					Attributes: row.Attributes,
+				}
+				id, errCreate := sectorSvc.dao.CreateInTx(tx, sector)
+				if errCreate != nil {
+					return errCreate
+				}
+				sector.Id = id
+				sectorsByCode[row.Code] = sector
+				report.Created++
+				continue
+			}
+
+			sector := sectorsByCode[row.Code]
+			if action.Moved {
+				parent := sectorsByCode[row.ParentCode]
+				errMove := checkUpdated(sectorSvc.dao.MoveInTx(tx, defaultTenantId, sector.Id, parent.Id, 0))
+				if errMove != nil {
+					return errMove
+				}
+				errRebase := sectorSvc.dao.RebaseSubtreeInTx(tx, sector.Id, parent.Id)
+				if errRebase != nil {
+					return errRebase
+				}
+				previousParents = append(previousParents, sector.ParentId.Int64)
+				report.Moved++
+			}
+			nbUpdated, errUpdate := sectorSvc.dao.ImportUpdateInTx(tx, defaultTenantId, sector.Id, row.Label, row.Status, row.Attributes)
+			if errUpdate != nil {
+				return errUpdate
+			}
+			report.Updated += nbUpdated
+		}
+		for _, parentId := range previousParents {
+			errCompact := sectorSvc.dao.CompactPositionsInTx(tx, parentId)
+			if errCompact != nil {
+				return errCompact
+			}
+		}
+		return nil
+	})
+	return report, sectorConflict(errTx)
+}
+
+// FindSectorsByTenantOrg returns the live sectors of the organization, restricted when statuses is not empty
+// to the branches whose sectors all have one of the statuses
+func (sectorSvc SectorService) FindSectorsByTenantOrg(defaultTenantId int64, orgId int64, statuses []model.SectorStatus) ([]model.Sector, error) {
+	if len(statuses) == 0 {
+		return sectorSvc.dao.FindSectorsByTenantOrg(defaultTenantId, orgId)
+	}
+	values := make([]int64, len(statuses))
+	for inc, status := range statuses {
+		values[inc] = int64(status)
+	}
+	return sectorSvc.dao.FindSectorsByStatus(defaultTenantId, orgId, values)
 }
 
 func (sectorSvc SectorService) FindByCode(defaultTenantId int64, code string) (model.Sector, error) {
 	return sectorSvc.dao.FindByCode(defaultTenantId, code)
 }
 
+// FindByLabelPath resolves a slash separated label path, starting below the root sector of the organization, one level at a time
+func (sectorSvc SectorService) FindByLabelPath(defaultTenantId int64, orgId int64, path string) (model.Sector, error) {
+	var nilSector model.Sector
+	segments, errSplit := helpers.SplitSectorLabelPath(path)
+	if errSplit != nil {
+		return nilSector, errSplit
+	}
+	sectorId, errRoot := sectorSvc.dao.FindRootSector(defaultTenantId, orgId)
+	if errRoot != nil {
+		return nilSector, errRoot
+	}
+	if sectorId == 0 {
+		return nilSector, errors.New(commons.SectorRootNotFound)
+	}
+	sectorCode := ""
+	for _, segment := range segments {
+		childId, childCode, errChild := sectorSvc.dao.FindSiblingByLabel(defaultTenantId, orgId, sectorId, segment)
+		if errChild != nil {
+			return nilSector, errChild
+		}
+		if childId == 0 {
+			return nilSector, helpers.SectorPathNotFoundError{Segment: segment}
+		}
+		sectorId, sectorCode = childId, childCode
+	}
+	if sectorCode == "" {
+		return sectorSvc.dao.FindById(defaultTenantId, sectorId)
+	}
+	return sectorSvc.dao.FindByCode(defaultTenantId, sectorCode)
+}
+
 func (sectorSvc SectorService) FindRootSectorId(defaultTenantId int64, orgId int64) (int64, error) {
 	return sectorSvc.dao.FindRootSector(defaultTenantId, orgId)
 }
 
-func (sectorSvc SectorService) DeleteSector(defaultTenantId int64, sectorId int64) error {
-	return sectorSvc.dao.DeleteSector(defaultTenantId, sectorId)
+func (sectorSvc SectorService) CountMembersBySector(defaultTenantId int64, orgId int64) (map[int64]int64, error) {
+	return sectorSvc.dao.CountMembersBySector(defaultTenantId, orgId)
+}
+
+// DeleteSector soft deletes the sector in one transaction. In cascade mode the whole subtree is deleted,
+// in reparent mode the direct children are attached to the parent of the deleted sector.
+// Assignments of users to deleted sectors are kept so that they come back on restore.
+func (sectorSvc SectorService) DeleteSector(defaultTenantId int64, sector model.Sector, options model.SectorDeleteOptions) (model.SectorDeletionReport, error) {
+	report := model.SectorDeletionReport{}
+	if options.Mode != model.SectorDeleteCascade && options.Mode != model.SectorDeleteReparent {
+		return report, errors.New(commons.SectorInvalidDeleteMode)
+	}
+	if !sector.HasParent {
+		return report, errors.New(commons.SectorDeleteRoot)
+	}
+	errVersion := checkVersion(options.Version, sector.Version)
+	if errVersion != nil {
+		return report, errVersion
+	}
+
+	deletedAt := time.Now()
+	errTx := sectorSvc.inTx(func(tx pgx.Tx) error {
+		if options.Mode == model.SectorDeleteCascade {
+			nbAssignments, errCount := sectorSvc.dao.CountSubtreeMembersInTx(tx, sector.Id)
+			if errCount != nil {
+				return errCount
+			}
+			nbSectors, errDelete := sectorSvc.dao.DeleteSubtreeInTx(tx, defaultTenantId, sector.Id, deletedAt, options.Version)
+			if errDelete = checkUpdated(nbSectors, errDelete); errDelete != nil {
+				return errDelete
+			}
+			report.Sectors = nbSectors
+			report.Assignments = nbAssignments
+			return sectorSvc.dao.CompactPositionsInTx(tx, sector.ParentId.Int64)
+		}
+
+		nbAssignments, errCount := sectorSvc.dao.CountMembersInTx(tx, sector.Id)
+		if errCount != nil {
+			return errCount
+		}
+		nbSectors, errDelete := sectorSvc.dao.DeleteInTx(tx, defaultTenantId, sector.Id, deletedAt, options.Version)
+		if errDelete = checkUpdated(nbSectors, errDelete); errDelete != nil {
+			return errDelete
+		}
+		childIds, errReparent := sectorSvc.dao.ReparentChildrenInTx(tx, sector.Id, sector.ParentId.Int64)
+		if errReparent != nil {
+			return errReparent
+		}
+		for _, childId := range childIds {
+			errRebase := sectorSvc.dao.RebaseSubtreeInTx(tx, childId, sector.ParentId.Int64)
+			if errRebase != nil {
+				return errRebase
+			}
+		}
+		report.Sectors = nbSectors
+		report.Reparented = int64(len(childIds))
+		report.Assignments = nbAssignments
+		return sectorSvc.dao.CompactPositionsInTx(tx, sector.ParentId.Int64)
+	})
+	return report, sectorConflict(errTx)
+}
+
+func (sectorSvc SectorService) Restore(defaultTenantId int64, orgId int64, code string) error {
+	sector, errFind := sectorSvc.dao.FindDeletedByCode(defaultTenantId, code)
+	if errors.Is(errFind, pgx.ErrNoRows) || (errFind == nil && sector.OrgId != orgId) {
+		return errors.New(commons.SectorNotFound)
+	}
+	if errFind != nil {
+		return errFind
+	}
+	if sector.HasParent {
+		parentExists, errParent := sectorSvc.dao.ExistsById(defaultTenantId, sector.ParentId.Int64)
+		if errParent != nil {
+			return errParent
+		}
+		if !parentExists {
+			return errors.New(commons.SectorParentDeleted)
+		}
+	}
+	errTx := sectorSvc.inTx(func(tx pgx.Tx) error {
+		errRestore := sectorSvc.dao.RestoreSectorInTx(tx, defaultTenantId, sector.Id, sector.DeletedAt.Time)
+		if errRestore != nil {
+			return errRestore
+		}
+		return sectorSvc.dao.CompactPositionsInTx(tx, sector.ParentId.Int64)
+	})
+	return sectorConflict(errTx)
+}
+
+// FindSubtree returns the sector of the organization followed by its descendants, at most maxDepth levels below it (negative for no limit)
+func (sectorSvc SectorService) FindSubtree(defaultTenantId int64, orgId int64, code string, maxDepth int) ([]model.Sector, error) {
+	sector, errFind := sectorSvc.dao.FindByCode(defaultTenantId, code)
+	if errors.Is(errFind, pgx.ErrNoRows) || (errFind == nil && sector.OrgId != orgId) {
+		return nil, errors.New(commons.SectorNotFound)
+	}
+	if errFind != nil {
+		return nil, errFind
+	}
+	absoluteDepth := -1
+	if maxDepth >= 0 {
+		absoluteDepth = sector.Depth + maxDepth
+	}
+	return sectorSvc.dao.FindSubtree(defaultTenantId, sector.Id, absoluteDepth)
+}
+
+func (sectorSvc SectorService) FindAncestors(defaultTenantId int64, sectorId int64) ([]model.Sector, error) {
+	return sectorSvc.dao.FindAncestors(defaultTenantId, sectorId)
+}
+
+// Move re-parents the sector after its new siblings and recomputes the path and depth of its whole subtree.
+// The root sector cannot move, and the new parent must belong to the same organization and not to the moved subtree.
+func (sectorSvc SectorService) Move(defaultTenantId int64, orgId int64, code string, parentCode string, version int64) error {
+	sector, errFind := sectorSvc.dao.FindByCode(defaultTenantId, code)
+	if errors.Is(errFind, pgx.ErrNoRows) || (errFind == nil && sector.OrgId != orgId) {
+		return errors.New(commons.SectorNotFound)
+	}
+	if errFind != nil {
+		return errFind
+	}
+	if !sector.HasParent {
+		return errors.New(commons.SectorMoveRoot)
+	}
+	errVersion := checkVersion(version, sector.Version)
+	if errVersion != nil {
+		return errVersion
+	}
+
+	parent, errParent := sectorSvc.dao.FindByCode(defaultTenantId, parentCode)
+	if errors.Is(errParent, pgx.ErrNoRows) {
+		return errors.New(commons.SectorParentNotFound)
+	}
+	if errParent != nil {
+		return errParent
+	}
+	if parent.OrgId != orgId {
+		return errors.New(commons.SectorMoveCrossOrg)
+	}
+	isCycle, errCycle := sectorSvc.dao.IsDescendantOf(defaultTenantId, parent.Id, sector.Id)
+	if errCycle != nil {
+		return errCycle
+	}
+	if isCycle {
+		return errors.New(commons.SectorMoveCycle)
+	}
+
+	errTx := sectorSvc.inTx(func(tx pgx.Tx) error {
+		errMove := checkUpdated(sectorSvc.dao.MoveInTx(tx, defaultTenantId, sector.Id, parent.Id, version))
+		if errMove != nil {
+			return errMove
+		}
+		errRebase := sectorSvc.dao.RebaseSubtreeInTx(tx, sector.Id, parent.Id)
+		if errRebase != nil {
+			return errRebase
+		}
+		return sectorSvc.dao.CompactPositionsInTx(tx, sector.ParentId.Int64)
+	})
+	return sectorConflict(errTx)
+}
+
+// errDryRun rolls back a reorganisation once it has been fully applied, so that a preview reports what would be written
+var errDryRun = errors.New("dry run")
+
+// Merge moves the children and the user assignments of the sector into the target sector, then deletes the sector, in one transaction.
+// The target keeps its own attribute values and takes the attributes only the merged sector had, checked against the schema of the
+// organization. The root sector cannot be merged and the target must be a live sector of the organization outside of the merged subtree.
+func (sectorSvc SectorService) Merge(defaultTenantId int64, orgId int64, code string, options model.SectorMergeOptions) (model.SectorReorganisationReport, error) {
+	report := model.SectorReorganisationReport{Code: options.TargetCode}
+	sector, errFind := sectorSvc.dao.FindByCode(defaultTenantId, code)
+	if errors.Is(errFind, pgx.ErrNoRows) || (errFind == nil && sector.OrgId != orgId) {
+		return report, errors.New(commons.SectorNotFound)
+	}
+	if errFind != nil {
+		return report, errFind
+	}
+	if !sector.HasParent {
+		return report, errors.New(commons.SectorMergeRoot)
+	}
+	errVersion := checkVersion(options.Version, sector.Version)
+	if errVersion != nil {
+		return report, errVersion
+	}
+
+	target, errTarget := sectorSvc.dao.FindByCode(defaultTenantId, options.TargetCode)
+	if errors.Is(errTarget, pgx.ErrNoRows) {
+		return report, errors.New(commons.SectorTargetNotFound)
+	}
+	if errTarget != nil {
+		return report, errTarget
+	}
+	if target.OrgId != orgId {
+		return report, errors.New(commons.SectorMoveCrossOrg)
+	}
+	isCycle, errCycle := sectorSvc.dao.IsDescendantOf(defaultTenantId, target.Id, sector.Id)
+	if errCycle != nil {
+		return report, errCycle
+	}
+	if isCycle || target.Id == sector.Id {
+		return report, errors.New(commons.SectorMergeCycle)
+	}
+	attributes := mergeAttributes(target.Attributes, sector.Attributes)
+	attributesErrors, errAttributes := sectorSvc.ValidateAttributes(defaultTenantId, orgId, attributes)
+	if errAttributes != nil {
+		return report, errAttributes
+	}
+	if len(attributesErrors) > 0 {
+		return report, helpers.SectorAttributesError{Errors: attributesErrors}
+	}
+
+	deletedAt := time.Now()
+	errTx := sectorSvc.inTx(func(tx pgx.Tx) error {
+		errDelete := checkUpdated(sectorSvc.dao.DeleteInTx(tx, defaultTenantId, sector.Id, deletedAt, options.Version))
+		if errDelete != nil {
+			return errDelete
+		}
+		childIds, errReparent := sectorSvc.dao.ReparentChildrenInTx(tx, sector.Id, target.Id)
+		if errReparent != nil {
+			return errReparent
+		}
+		for _, childId := range childIds {
+			errRebase := sectorSvc.dao.RebaseSubtreeInTx(tx, childId, target.Id)
+			if errRebase != nil {
+				return errRebase
+			}
+		}
+		// Every user of the merged sector ends up in the target, including those already assigned to it
+		nbAssignments, errCount := sectorSvc.dao.CountMembersInTx(tx, sector.Id)
+		if errCount != nil {
+			return errCount
+		}
+		errMembers := sectorSvc.dao.MoveMembersInTx(tx, sector.Id, target.Id)
+		if errMembers != nil {
+			return errMembers
+		}
+		if len(attributes) > len(target.Attributes) {
+			errAttributes := sectorSvc.dao.UpdateAttributesInTx(tx, target.Id, attributes)
+			if errAttributes != nil {
+				return errAttributes
+			}
+		}
+		errCompact := sectorSvc.dao.CompactPositionsInTx(tx, sector.ParentId.Int64)
+		if errCompact != nil {
+			return errCompact
+		}
+		report.Children = int64(len(childIds))
+		report.Assignments = nbAssignments
+		if options.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(errTx, errDryRun) {
+		return report, nil
+	}
+	return report, sectorConflict(errTx)
+}
+
+// mergeAttributes returns the attributes of the target completed with the attributes it does not have
+func mergeAttributes(target map[string]any, merged map[string]any) map[string]any {
+	attributes := make(map[string]any, len(target)+len(merged))
+	for name, value := range merged {
+		attributes[name] = value
+	}
+	for name, value := range target {
+		attributes[name] = value
+	}
+	return attributes
+}
+
+// Split creates a sibling of the sector, placed right after it with the same status and attributes (checked against the schema
+// of the organization), and moves into it
+// the chosen children and users of the sector in one transaction. Children must be direct children of the sector and users
+// must be assigned to it.
+func (sectorSvc SectorService) Split(defaultTenantId int64, orgId int64, code string, options model.SectorSplitOptions) (model.SectorReorganisationReport, error) {
+	report := model.SectorReorganisationReport{Code: options.Code}
+	sector, errFind := sectorSvc.dao.FindByCode(defaultTenantId, code)
+	if errors.Is(errFind, pgx.ErrNoRows) || (errFind == nil && sector.OrgId != orgId) {
+		return report, errors.New(commons.SectorNotFound)
+	}
+	if errFind != nil {
+		return report, errFind
+	}
+	if !sector.HasParent {
+		return report, errors.New(commons.SectorSplitRoot)
+	}
+	errVersion := checkVersion(options.Version, sector.Version)
+	if errVersion != nil {
+		return report, errVersion
+	}
+
+	children, errChildren := sectorSvc.dao.FindChildren(defaultTenantId, sector.Id)
+	if errChildren != nil {
+		return report, errChildren
+	}
+	childrenByCode := make(map[string]model.Sector, len(children))
+	for _, child := range children {
+		childrenByCode[child.Code] = child
+	}
+	movedChildren := make([]model.Sector, 0, len(options.ChildCodes))
+	for _, childCode := range options.ChildCodes {
+		child, found := childrenByCode[childCode]
+		if !found {
+			return report, errors.New(commons.SectorSplitInvalid)
+		}
+		delete(childrenByCode, childCode)
+		movedChildren = append(movedChildren, child)
+	}
+	// The new sector takes the attributes of the split sector, which may predate the current schema of the organization
+	attributesErrors, errAttributes := sectorSvc.ValidateAttributes(defaultTenantId, orgId, sector.Attributes)
+	if errAttributes != nil {
+		return report, errAttributes
+	}
+	if len(attributesErrors) > 0 {
+		return report, helpers.SectorAttributesError{Errors: attributesErrors}
+	}
+	userIds := make([]int64, len(options.UserIds))
+	for inc, userExtId := range options.UserIds {
+		userId, errUser := sectorSvc.findUserId(defaultTenantId, userExtId)
+		if errUser != nil {
+			return report, errUser
+		}
+		userIds[inc] = userId
+	}
+
+	errTx := sectorSvc.inTx(func(tx pgx.Tx) error {
+		sibling := model.Sector{
+			TenantId:   defaultTenantId,
+			OrgId:      orgId,
+			Code:       options.Code,
+			Label:      options.Label,
+			ParentId:   sector.ParentId,
+			HasParent:  true,
+			Depth:      sector.Depth,
+			Position:   sector.Position + 1,
+			Status:     sector.Status,
+			Attributes: sector.Attributes,
+		}
+		errShift := sectorSvc.dao.ShiftPositionsInTx(tx, sector.ParentId.Int64, sibling.Position)
+		if errShift != nil {
+			return errShift
+		}
+		siblingId, errCreate := sectorSvc.dao.CreateInTx(tx, sibling)
+		if errCreate != nil {
+			return errCreate
+		}
+		for _, child := range movedChildren {
+			errMove := checkUpdated(sectorSvc.dao.MoveInTx(tx, defaultTenantId, child.Id, siblingId, 0))
+			if errMove != nil {
+				return errMove
+			}
+			errRebase := sectorSvc.dao.RebaseSubtreeInTx(tx, child.Id, siblingId)
+			if errRebase != nil {
+				return errRebase
+			}
+		}
+		errCompact := sectorSvc.dao.CompactPositionsInTx(tx, sector.Id)
+		if errCompact != nil {
+			return errCompact
+		}
+		for _, userId := range userIds {
+			nbMoved, errMember := sectorSvc.dao.MoveMemberInTx(tx, userId, sector.Id, siblingId)
+			if errMember != nil {
+				return errMember
+			}
+			if nbMoved == 0 {
+				return errors.New(commons.SectorSplitInvalid)
+			}
+		}
+		report.Children = int64(len(movedChildren))
+		report.Assignments = int64(len(userIds))
+		if options.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(errTx, errDryRun) {
+		return report, nil
+	}
+	return report, sectorConflict(errTx)
+}
+
+// ChangeStatus activates or deactivates the sector, and its live descendants in cascade mode.
+// A sector cannot be activated below an inactive ancestor, nor deactivated while active users are assigned unless forced.
+func (sectorSvc SectorService) ChangeStatus(defaultTenantId int64, orgId int64, code string, status model.SectorStatus, options model.SectorStatusOptions) error {
+	sector, errFind := sectorSvc.dao.FindByCode(defaultTenantId, code)
+	if errors.Is(errFind, pgx.ErrNoRows) || (errFind == nil && sector.OrgId != orgId) {
+		return errors.New(commons.SectorNotFound)
+	}
+	if errFind != nil {
+		return errFind
+	}
+	errVersion := checkVersion(options.Version, sector.Version)
+	if errVersion != nil {
+		return errVersion
+	}
+	if status == model.SectorStatusActive {
+		ancestors, errAncestors := sectorSvc.dao.FindAncestors(defaultTenantId, sector.Id)
+		if errAncestors != nil {
+			return errAncestors
+		}
+		for _, ancestor := range ancestors {
+			if ancestor.Status == model.SectorStatusInactive {
+				return errors.New(commons.SectorParentInactive)
+			}
+		}
+	}
+
+	return sectorSvc.inTx(func(tx pgx.Tx) error {
+		if status == model.SectorStatusInactive && !options.Force {
+			nbMembers, errCount := sectorSvc.dao.CountActiveMembersInTx(tx, sector.Id, options.Cascade)
+			if errCount != nil {
+				return errCount
+			}
+			if nbMembers > 0 {
+				return errors.New(commons.SectorHasActiveMembers)
+			}
+		}
+		errUpdate := checkUpdated(sectorSvc.dao.UpdateStatusInTx(tx, defaultTenantId, sector.Id, status, options.Version))
+		if errUpdate != nil || !options.Cascade {
+			return errUpdate
+		}
+		_, errDescendants := sectorSvc.dao.UpdateDescendantsStatusInTx(tx, defaultTenantId, sector.Id, status)
+		return errDescendants
+	})
 }
 
-func (sectorSvc SectorService) Update(defaultTenantId int64, id int64, label string) error {
-	return sectorSvc.dao.Update(defaultTenantId, id, label)
+// Reorder sets the positions of the children of the sector, codes must list every live child exactly once
+func (sectorSvc SectorService) Reorder(defaultTenantId int64, orgId int64, code string, childCodes []string) error {
+	sector, errFind := sectorSvc.dao.FindByCode(defaultTenantId, code)
+	if errors.Is(errFind, pgx.ErrNoRows) || (errFind == nil && sector.OrgId != orgId) {
+		return errors.New(commons.SectorNotFound)
+	}
+	if errFind != nil {
+		return errFind
+	}
+	children, errChildren := sectorSvc.dao.FindChildren(defaultTenantId, sector.Id)
+	if errChildren != nil {
+		return errChildren
+	}
+	if len(children) != len(childCodes) {
+		return errors.New(commons.SectorReorderInvalid)
+	}
+	pending := make(map[string]bool, len(children))
+	for _, child := range children {
+		pending[child.Code] = true
+	}
+	for _, childCode := range childCodes {
+		if !pending[childCode] {
+			return errors.New(commons.SectorReorderInvalid)
+		}
+		delete(pending, childCode)
+	}
+	return sectorSvc.dao.ReorderChildren(defaultTenantId, sector.Id, childCodes)
 }
 
-func (sectorSvc SectorService) FindByLabel(defaultTenantId int64, label string) (int64, string, error) {
-	return sectorSvc.dao.FindByLabel(defaultTenantId, label)
+// Update sets the label of the sector, and its attributes unless nil
+func (sectorSvc SectorService) Update(defaultTenantId int64, sector model.Sector, label string, attributes map[string]any, version int64) error {
+	if sector.HasParent {
+		idSibling, _, errSibling := sectorSvc.dao.FindSiblingByLabel(defaultTenantId, sector.OrgId, sector.ParentId.Int64, label)
+		if errSibling != nil {
+			return errSibling
+		}
+		if idSibling > 0 && idSibling != sector.Id {
+			return errors.New(commons.SectorAlreadyExist)
+		}
+	}
+	return sectorConflict(checkUpdated(sectorSvc.dao.Update(defaultTenantId, sector.Id, label, attributes, version)))
+}
+
+// FindAttributeSchema returns the attribute definitions of the sectors of the organization, empty when none were saved
+func (sectorSvc SectorService) FindAttributeSchema(defaultTenantId int64, orgId int64) ([]model.SectorAttributeDef, error) {
+	schema, errFind := sectorSvc.schemaDao.FindByOrgId(defaultTenantId, orgId)
+	if errors.Is(errFind, pgx.ErrNoRows) {
+		return []model.SectorAttributeDef{}, nil
+	}
+	if errFind != nil {
+		return nil, errFind
+	}
+	return schema.Attributes, nil
+}
+
+// SaveAttributeSchema replaces the attribute definitions of the organization, values already stored on sectors are checked on their next update
+func (sectorSvc SectorService) SaveAttributeSchema(defaultTenantId int64, orgId int64, defs []model.SectorAttributeDef) error {
+	if defs == nil {
+		defs = []model.SectorAttributeDef{}
+	}
+	return sectorSvc.schemaDao.Save(model.SectorAttributeSchema{OrgId: orgId, TenantId: defaultTenantId, Attributes: defs})
+}
+
+// ValidateAttributes checks attribute values against the schema of the organization
+func (sectorSvc SectorService) ValidateAttributes(defaultTenantId int64, orgId int64, values map[string]any) ([]validation.ErrorValidation, error) {
+	defs, errSchema := sectorSvc.FindAttributeSchema(defaultTenantId, orgId)
+	if errSchema != nil {
+		return nil, errSchema
+	}
+	return helpers.ValidateSectorAttributes(defs, values), nil
+}
+
+func (sectorSvc SectorService) AddMember(defaultTenantId int64, sectorId int64, userExtId string) error {
+	userId, errUser := sectorSvc.findUserId(defaultTenantId, userExtId)
+	if errUser != nil {
+		return errUser
+	}
+	return sectorSvc.dao.AddMember(defaultTenantId, sectorId, userId)
+}
+
+func (sectorSvc SectorService) RemoveMember(defaultTenantId int64, sectorId int64, userExtId string) error {
+	userId, errUser := sectorSvc.findUserId(defaultTenantId, userExtId)
+	if errUser != nil {
+		return errUser
+	}
+	return sectorSvc.dao.RemoveMember(defaultTenantId, sectorId, userId)
+}
+
+// findUserId resolves a user of the tenant, memberships are not restricted to the user's own organization
+func (sectorSvc SectorService) findUserId(defaultTenantId int64, userExtId string) (int64, error) {
+	userId, errFind := sectorSvc.userDao.FindIdByExternalId(defaultTenantId, userExtId)
+	if errors.Is(errFind, pgx.ErrNoRows) {
+		return 0, errors.New(commons.UserNotFound)
+	}
+	return userId, errFind
 }
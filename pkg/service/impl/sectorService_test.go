@@ -0,0 +1,234 @@
+package impl
+
+import (
+	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/helpers"
+	"micro-fiber-test/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMemSectorService(db *memDb) SectorService {
+	return SectorService{dao: memSectorDao{db: db}, userDao: memUserDao{db: db}, schemaDao: memSchemaDao{db: db}, inTx: noTx}
+}
+
+func TestSectorRestoreKeepsStatuses(t *testing.T) {
+	db := &memDb{}
+	db.addOrg(1, model.OrgStatusActive)
+	db.addSector(10, 1, 0, "Root", model.SectorStatusActive)
+	sales := db.addSector(11, 1, 10, "Sales", model.SectorStatusActive)
+	db.addSector(12, 1, 11, "North", model.SectorStatusInactive)
+	db.addSector(13, 1, 11, "South", model.SectorStatusDraft)
+	sectorSvc := newMemSectorService(db)
+
+	report, err := sectorSvc.DeleteSector(1, sales.Sector, model.SectorDeleteOptions{Mode: model.SectorDeleteCascade})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), report.Sectors)
+	assert.Equal(t, model.SectorStatusDeleted, db.sector(12).Status)
+
+	assert.Nil(t, sectorSvc.Restore(1, 1, "s11"))
+	assert.Equal(t, model.SectorStatusActive, db.sector(11).Status)
+	assert.Equal(t, model.SectorStatusInactive, db.sector(12).Status)
+	assert.Equal(t, model.SectorStatusDraft, db.sector(13).Status)
+	assert.False(t, db.sector(13).DeletedAt.Valid)
+}
+
+func TestSectorMove(t *testing.T) {
+	db := &memDb{}
+	db.addOrg(1, model.OrgStatusActive)
+	db.addSector(10, 1, 0, "Root", model.SectorStatusActive)
+	db.addSector(11, 1, 10, "A", model.SectorStatusActive)
+	db.addSector(12, 1, 10, "B", model.SectorStatusActive)
+	db.addSector(13, 1, 10, "C", model.SectorStatusActive)
+	db.addSector(14, 1, 11, "A1", model.SectorStatusActive)
+	db.addSector(15, 1, 14, "A11", model.SectorStatusActive)
+	db.addSector(16, 1, 13, "C1", model.SectorStatusActive)
+	sectorSvc := newMemSectorService(db)
+
+	assert.EqualError(t, sectorSvc.Move(1, 1, "s11", "s15", 0), commons.SectorMoveCycle)
+	assert.EqualError(t, sectorSvc.Move(1, 1, "s11", "s11", 0), commons.SectorMoveCycle)
+	assert.EqualError(t, sectorSvc.Move(1, 1, "s10", "s13", 0), commons.SectorMoveRoot)
+	assert.EqualError(t, sectorSvc.Move(1, 1, "s11", "s13", 5), commons.VersionMismatch)
+
+	assert.Nil(t, sectorSvc.Move(1, 1, "s11", "s13", 1))
+	assert.Equal(t, []string{"B", "C"}, db.childLabels(10))
+	assert.Equal(t, 1, db.sector(13).Position)
+	assert.Equal(t, []string{"C1", "A"}, db.childLabels(13))
+	assert.Equal(t, "/10/13/11/", db.sector(11).Path)
+	assert.Equal(t, 2, db.sector(11).Depth)
+	assert.Equal(t, "/10/13/11/14/15/", db.sector(15).Path)
+	assert.Equal(t, 4, db.sector(15).Depth)
+}
+
+func TestSectorDeleteReparent(t *testing.T) {
+	db := &memDb{}
+	db.addOrg(1, model.OrgStatusActive)
+	db.addSector(10, 1, 0, "Root", model.SectorStatusActive)
+	a := db.addSector(11, 1, 10, "A", model.SectorStatusActive)
+	db.addSector(12, 1, 10, "B", model.SectorStatusActive)
+	db.addSector(14, 1, 11, "A1", model.SectorStatusActive)
+	db.addSector(15, 1, 14, "A11", model.SectorStatusActive)
+	db.addSector(16, 1, 11, "A2", model.SectorStatusActive)
+	db.addUser(100, 1, model.UserStatusActive, 11)
+	sectorSvc := newMemSectorService(db)
+
+	report, err := sectorSvc.DeleteSector(1, a.Sector, model.SectorDeleteOptions{Mode: model.SectorDeleteReparent})
+	assert.Nil(t, err)
+	assert.Equal(t, model.SectorDeletionReport{Sectors: 1, Reparented: 2, Assignments: 1}, report)
+	assert.Equal(t, []string{"B", "A1", "A2"}, db.childLabels(10))
+	for position, child := range db.liveChildren(10) {
+		assert.Equal(t, position, child.Position)
+	}
+	assert.Equal(t, "/10/14/", db.sector(14).Path)
+	assert.Equal(t, 1, db.sector(14).Depth)
+	assert.Equal(t, "/10/14/15/", db.sector(15).Path)
+	assert.Equal(t, 2, db.sector(15).Depth)
+	assert.Equal(t, 1, db.sector(16).Depth)
+}
+
+func TestSectorDeleteCascadeCompactsPositions(t *testing.T) {
+	db := &memDb{}
+	db.addOrg(1, model.OrgStatusActive)
+	root := db.addSector(10, 1, 0, "Root", model.SectorStatusActive)
+	db.addSector(11, 1, 10, "A", model.SectorStatusActive)
+	b := db.addSector(12, 1, 10, "B", model.SectorStatusActive)
+	db.addSector(13, 1, 10, "C", model.SectorStatusActive)
+	db.addSector(14, 1, 12, "B1", model.SectorStatusActive)
+	sectorSvc := newMemSectorService(db)
+
+	_, errRoot := sectorSvc.DeleteSector(1, root.Sector, model.SectorDeleteOptions{Mode: model.SectorDeleteCascade})
+	assert.EqualError(t, errRoot, commons.SectorDeleteRoot)
+
+	report, err := sectorSvc.DeleteSector(1, b.Sector, model.SectorDeleteOptions{Mode: model.SectorDeleteCascade})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), report.Sectors)
+	assert.Equal(t, []string{"A", "C"}, db.childLabels(10))
+	assert.Equal(t, 1, db.sector(13).Position)
+
+	// The restored sector takes its former place back among its siblings
+	assert.Nil(t, sectorSvc.Restore(1, 1, "s12"))
+	assert.Equal(t, []string{"A", "B", "C"}, db.childLabels(10))
+	assert.Equal(t, 2, db.sector(13).Position)
+	assert.False(t, db.sector(14).DeletedAt.Valid)
+}
+
+func TestSectorRestoreUnderDeletedParent(t *testing.T) {
+	db := &memDb{}
+	db.addOrg(1, model.OrgStatusActive)
+	db.addSector(10, 1, 0, "Root", model.SectorStatusActive)
+	a := db.addSector(11, 1, 10, "A", model.SectorStatusActive)
+	a1 := db.addSector(12, 1, 11, "A1", model.SectorStatusActive)
+	sectorSvc := newMemSectorService(db)
+
+	_, err := sectorSvc.DeleteSector(1, a1.Sector, model.SectorDeleteOptions{Mode: model.SectorDeleteCascade})
+	assert.Nil(t, err)
+	_, err = sectorSvc.DeleteSector(1, db.sector(a.Id).Sector, model.SectorDeleteOptions{Mode: model.SectorDeleteCascade})
+	assert.Nil(t, err)
+	assert.EqualError(t, sectorSvc.Restore(1, 1, "s12"), commons.SectorParentDeleted)
+	assert.EqualError(t, sectorSvc.Restore(1, 2, "s11"), commons.SectorNotFound)
+}
+
+func TestSectorMerge(t *testing.T) {
+	db := &memDb{schemas: map[int64][]model.SectorAttributeDef{1: {
+		{Name: "region", Type: model.SectorAttributeString, AllowedValues: []string{"emea", "apac"}},
+		{Name: "costCentre", Type: model.SectorAttributeString},
+	}}}
+	db.addOrg(1, model.OrgStatusActive)
+	db.addSector(10, 1, 0, "Root", model.SectorStatusActive)
+	a := db.addSector(11, 1, 10, "A", model.SectorStatusActive)
+	a.Attributes = map[string]any{"region": "emea", "costCentre": "CC1"}
+	b := db.addSector(12, 1, 10, "B", model.SectorStatusActive)
+	b.Attributes = map[string]any{"region": "apac"}
+	c := db.addSector(13, 1, 10, "C", model.SectorStatusActive)
+	c.Attributes = map[string]any{"legacy": true}
+	db.addSector(14, 1, 11, "A1", model.SectorStatusActive)
+	db.addSector(15, 1, 12, "B1", model.SectorStatusActive)
+	db.addUser(100, 1, model.UserStatusActive, 11)
+	db.addUser(101, 1, model.UserStatusActive, 11, 12)
+	db.addUser(102, 1, model.UserStatusActive, 12)
+	sectorSvc := newMemSectorService(db)
+
+	_, errRoot := sectorSvc.Merge(1, 1, "s10", model.SectorMergeOptions{TargetCode: "s12"})
+	assert.EqualError(t, errRoot, commons.SectorMergeRoot)
+	_, errDescendant := sectorSvc.Merge(1, 1, "s12", model.SectorMergeOptions{TargetCode: "s15"})
+	assert.EqualError(t, errDescendant, commons.SectorMergeCycle)
+	_, errSelf := sectorSvc.Merge(1, 1, "s12", model.SectorMergeOptions{TargetCode: "s12"})
+	assert.EqualError(t, errSelf, commons.SectorMergeCycle)
+	// The attributes taken from the merged sector must fit the schema of the organization
+	_, errAttributes := sectorSvc.Merge(1, 1, "s13", model.SectorMergeOptions{TargetCode: "s12"})
+	assert.IsType(t, helpers.SectorAttributesError{}, errAttributes)
+	assert.False(t, db.sector(13).DeletedAt.Valid)
+
+	report, err := sectorSvc.Merge(1, 1, "s11", model.SectorMergeOptions{TargetCode: "s12", Version: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), report.Children)
+	assert.True(t, db.sector(11).DeletedAt.Valid)
+	assert.Equal(t, []int64{100, 101, 102}, db.sectorMembers(12))
+	assert.Empty(t, db.sectorMembers(11))
+	assert.Equal(t, map[string]any{"region": "apac", "costCentre": "CC1"}, db.sector(12).Attributes)
+	assert.Equal(t, []string{"B1", "A1"}, db.childLabels(12))
+	assert.Equal(t, "/10/12/14/", db.sector(14).Path)
+	assert.Equal(t, []string{"B", "C"}, db.childLabels(10))
+	assert.Equal(t, 0, db.sector(12).Position)
+}
+
+func TestSectorSplit(t *testing.T) {
+	db := &memDb{schemas: map[int64][]model.SectorAttributeDef{1: {
+		{Name: "region", Type: model.SectorAttributeString, Required: true},
+	}}}
+	db.addOrg(1, model.OrgStatusActive)
+	db.addSector(10, 1, 0, "Root", model.SectorStatusActive)
+	a := db.addSector(11, 1, 10, "A", model.SectorStatusActive)
+	a.Attributes = map[string]any{"region": "emea"}
+	db.addSector(12, 1, 10, "B", model.SectorStatusActive)
+	db.addSector(13, 1, 11, "A1", model.SectorStatusActive)
+	db.addSector(14, 1, 11, "A2", model.SectorStatusActive)
+	db.addSector(15, 1, 11, "A3", model.SectorStatusActive)
+	db.addSector(16, 1, 14, "A21", model.SectorStatusActive)
+	db.addUser(100, 1, model.UserStatusActive, 11)
+	db.addUser(101, 1, model.UserStatusActive, 11)
+	sectorSvc := newMemSectorService(db)
+
+	// B predates the schema and lacks the required region, so its attributes cannot be given to a new sector
+	_, errAttributes := sectorSvc.Split(1, 1, "s12", model.SectorSplitOptions{Code: "b2", Label: "B2"})
+	assert.IsType(t, helpers.SectorAttributesError{}, errAttributes)
+	_, errChild := sectorSvc.Split(1, 1, "s11", model.SectorSplitOptions{Code: "a-bis", Label: "A bis", ChildCodes: []string{"s16"}})
+	assert.EqualError(t, errChild, commons.SectorSplitInvalid)
+	_, errUser := sectorSvc.Split(1, 1, "s11", model.SectorSplitOptions{Code: "a-bis", Label: "A bis", UserIds: []string{"u999"}})
+	assert.EqualError(t, errUser, commons.UserNotFound)
+	assert.Len(t, db.sectors, 7)
+
+	report, err := sectorSvc.Split(1, 1, "s11", model.SectorSplitOptions{Code: "a-bis", Label: "A bis", ChildCodes: []string{"s14"}, UserIds: []string{"u101"}, Version: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, model.SectorReorganisationReport{Code: "a-bis", Children: 1, Assignments: 1}, report)
+	assert.Equal(t, []string{"A", "A bis", "B"}, db.childLabels(10))
+	sibling, errFind := sectorSvc.dao.FindByCode(1, "a-bis")
+	assert.Nil(t, errFind)
+	assert.Equal(t, map[string]any{"region": "emea"}, sibling.Attributes)
+	assert.Equal(t, []string{"A1", "A3"}, db.childLabels(11))
+	assert.Equal(t, 1, db.sector(15).Position)
+	assert.Equal(t, []string{"A2"}, db.childLabels(sibling.Id))
+	assert.Equal(t, "/10/17/14/16/", db.sector(16).Path)
+	assert.Equal(t, []int64{100}, db.sectorMembers(11))
+	assert.Equal(t, []int64{101}, db.sectorMembers(sibling.Id))
+}
+
+func TestSectorMergeReportCountsExistingAssignments(t *testing.T) {
+	db := &memDb{}
+	db.addOrg(1, model.OrgStatusActive)
+	db.addSector(10, 1, 0, "Root", model.SectorStatusActive)
+	db.addSector(11, 1, 10, "A", model.SectorStatusActive)
+	db.addSector(12, 1, 10, "B", model.SectorStatusActive)
+	db.addUser(100, 1, model.UserStatusActive, 11, 12)
+	db.addUser(101, 1, model.UserStatusActive, 11, 12)
+	db.addUser(102, 1, model.UserStatusActive, 11)
+	sectorSvc := newMemSectorService(db)
+
+	// Users already assigned to the target keep a single assignment but are still reported
+	report, err := sectorSvc.Merge(1, 1, "s11", model.SectorMergeOptions{TargetCode: "s12"})
+	assert.Nil(t, err)
+	assert.Equal(t, model.SectorReorganisationReport{Code: "s12", Assignments: 3}, report)
+	assert.Equal(t, []int64{100, 101, 102}, db.sectorMembers(12))
+	assert.Len(t, db.members, 3)
+}
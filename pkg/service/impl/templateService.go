@@ -0,0 +1,51 @@
+package impl
+
+import (
+	"errors"
+	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/model"
+	daoApi "micro-fiber-test/pkg/repository/api"
+	svcApi "micro-fiber-test/pkg/service/api"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type TemplateService struct {
+	templateDao daoApi.TemplateDaoInterface
+}
+
+func NewTemplateService(templateDao daoApi.TemplateDaoInterface) svcApi.TemplateServiceInterface {
+	return &TemplateService{templateDao: templateDao}
+}
+
+func (templateSvc *TemplateService) Create(tenantId int64, template model.SectorTemplate) (int64, error) {
+	template.TenantId = tenantId
+	exists, errExists := templateSvc.templateDao.ExistsByLabel(tenantId, template.Label)
+	if errExists != nil {
+		return 0, errExists
+	}
+	if exists {
+		return 0, errors.New(commons.TemplateAlreadyExists)
+	}
+	return templateSvc.templateDao.Create(template)
+}
+
+func (templateSvc *TemplateService) Delete(tenantId int64, code string) error {
+	_, errFind := templateSvc.FindByCode(tenantId, code)
+	if errFind != nil {
+		return errFind
+	}
+	return templateSvc.templateDao.Delete(tenantId, code)
+}
+
+func (templateSvc *TemplateService) FindByCode(tenantId int64, code string) (model.SectorTemplate, error) {
+	template, errFind := templateSvc.templateDao.FindByCode(tenantId, code)
+	if errors.Is(errFind, pgx.ErrNoRows) {
+		return template, errors.New(commons.TemplateNotFound)
+	}
+	return template, errFind
+}
+
+func (templateSvc *TemplateService) FindAll(tenantId int64) ([]model.SectorTemplate, error) {
+	return templateSvc.templateDao.FindAll(tenantId)
+}
@@ -0,0 +1,100 @@
+package impl
+
+import (
+	"errors"
+	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/model"
+	"micro-fiber-test/pkg/repository/api"
+	svcApi "micro-fiber-test/pkg/service/api"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type TenantService struct {
+	dao api.TenantDaoInterface
+}
+
+func NewTenantService(daoP api.TenantDaoInterface) svcApi.TenantServiceInterface {
+	return &TenantService{dao: daoP}
+}
+
+func (tenantSvc TenantService) Create(tenant model.Tenant) (int64, error) {
+	exists, errExists := tenantSvc.dao.ExistsByCode(tenant.Code)
+	if errExists != nil {
+		return 0, errExists
+	}
+	if exists {
+		return 0, errors.New(commons.TenantAlreadyExists)
+	}
+	tenant.Status = model.TenantStatusActive
+	return tenantSvc.dao.Create(tenant)
+}
+
+func (tenantSvc TenantService) Rename(code string, label string) error {
+	if _, errFind := tenantSvc.FindByCode(code); errFind != nil {
+		return errFind
+	}
+	return tenantSvc.dao.Update(code, label)
+}
+
+func (tenantSvc TenantService) Suspend(code string) error {
+	if _, errFind := tenantSvc.FindByCode(code); errFind != nil {
+		return errFind
+	}
+	return tenantSvc.dao.UpdateStatus(code, model.TenantStatusSuspended)
+}
+
+func (tenantSvc TenantService) Activate(code string) error {
+	if _, errFind := tenantSvc.FindByCode(code); errFind != nil {
+		return errFind
+	}
+	return tenantSvc.dao.UpdateStatus(code, model.TenantStatusActive)
+}
+
+func (tenantSvc TenantService) Delete(code string) error {
+	tenant, errFind := tenantSvc.FindByCode(code)
+	if errFind != nil {
+		return errFind
+	}
+	// Organizations reference the tenant, refuse to orphan them
+	nbOrgs, errCount := tenantSvc.dao.CountOrganizations(tenant.Id)
+	if errCount != nil {
+		return errCount
+	}
+	if nbOrgs > 0 {
+		return errors.New(commons.TenantNotEmpty)
+	}
+	// Sector templates and attribute schemas reference the tenant as well
+	nbSettings, errSettings := tenantSvc.dao.CountSectorSettings(tenant.Id)
+	if errSettings != nil {
+		return errSettings
+	}
+	if nbSettings > 0 {
+		return errors.New(commons.TenantNotEmpty)
+	}
+	return tenantSvc.dao.Delete(code)
+}
+
+func (tenantSvc TenantService) FindById(id int64) (model.Tenant, error) {
+	tenant, err := tenantSvc.dao.FindById(id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return tenant, errors.New(commons.TenantNotFound)
+	}
+	return tenant, err
+}
+
+func (tenantSvc TenantService) FindByCode(code string) (model.Tenant, error) {
+	tenant, err := tenantSvc.dao.FindByCode(code)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return tenant, errors.New(commons.TenantNotFound)
+	}
+	return tenant, err
+}
+
+func (tenantSvc TenantService) FindAll() ([]model.Tenant, error) {
+	return tenantSvc.dao.FindAll()
+}
+
+func (tenantSvc TenantService) FindCodesByUserLogin(login string) ([]string, error) {
+	return tenantSvc.dao.FindCodesByUserLogin(login)
+}
@@ -0,0 +1,52 @@
+package impl
+
+import (
+	"micro-fiber-test/pkg/dto/commons"
+	"micro-fiber-test/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tenantDaoStub is an in memory tenant DAO holding a single tenant and its dependent rows counts
+type tenantDaoStub struct {
+	tenant     model.Tenant
+	nbOrgs     int
+	nbSettings int
+	deleted    bool
+}
+
+func (d *tenantDaoStub) Create(tenant model.Tenant) (int64, error)                 { return 0, nil }
+func (d *tenantDaoStub) Update(code string, label string) error                    { return nil }
+func (d *tenantDaoStub) UpdateStatus(code string, status model.TenantStatus) error { return nil }
+func (d *tenantDaoStub) Delete(code string) error {
+	d.deleted = true
+	return nil
+}
+func (d *tenantDaoStub) FindById(id int64) (model.Tenant, error)        { return d.tenant, nil }
+func (d *tenantDaoStub) FindByCode(code string) (model.Tenant, error)   { return d.tenant, nil }
+func (d *tenantDaoStub) FindAll() ([]model.Tenant, error)               { return []model.Tenant{d.tenant}, nil }
+func (d *tenantDaoStub) ExistsByCode(code string) (bool, error)         { return true, nil }
+func (d *tenantDaoStub) CountOrganizations(tenantId int64) (int, error) { return d.nbOrgs, nil }
+func (d *tenantDaoStub) CountSectorSettings(tenantId int64) (int, error) {
+	return d.nbSettings, nil
+}
+func (d *tenantDaoStub) FindCodesByUserLogin(login string) ([]string, error) {
+	return []string{d.tenant.Code}, nil
+}
+
+func TestTenantDelete(t *testing.T) {
+	tenant := model.Tenant{Id: 2, Code: "acme", Status: model.TenantStatusActive}
+
+	withOrgs := &tenantDaoStub{tenant: tenant, nbOrgs: 1}
+	assert.EqualError(t, NewTenantService(withOrgs).Delete("acme"), commons.TenantNotEmpty)
+	assert.False(t, withOrgs.deleted)
+
+	withSettings := &tenantDaoStub{tenant: tenant, nbSettings: 2}
+	assert.EqualError(t, NewTenantService(withSettings).Delete("acme"), commons.TenantNotEmpty)
+	assert.False(t, withSettings.deleted)
+
+	empty := &tenantDaoStub{tenant: tenant}
+	assert.NoError(t, NewTenantService(empty).Delete("acme"))
+	assert.True(t, empty.deleted)
+}
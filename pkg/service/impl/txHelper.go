@@ -0,0 +1,36 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// txRunner runs fn in a transaction, services hold one so that tests can run them without database
+type txRunner func(fn func(tx pgx.Tx) error) error
+
+// poolTxRunner runs transactions on connections of the pool
+func poolTxRunner(pool *pgxpool.Pool) txRunner {
+	return func(fn func(tx pgx.Tx) error) error {
+		return runInTx(pool, fn)
+	}
+}
+
+// runInTx executes fn in a read-write transaction, committed when fn succeeds and rolled back otherwise
+func runInTx(pool *pgxpool.Pool, fn func(tx pgx.Tx) error) error {
+	tx, errTx := pool.BeginTx(context.Background(), pgx.TxOptions{AccessMode: pgx.ReadWrite, IsoLevel: pgx.RepeatableRead})
+	if errTx != nil {
+		return errTx
+	}
+	if errFn := fn(tx); errFn != nil {
+		errRbk := tx.Rollback(context.Background())
+		if errRbk != nil {
+			fullErr := fmt.Errorf("error rolling back connection [%w]", errRbk)
+			fmt.Printf("Rollback error [%s]", fullErr)
+		}
+		return errFn
+	}
+	return tx.Commit(context.Background())
+}
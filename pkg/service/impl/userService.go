@@ -6,6 +6,8 @@ import (
 	"micro-fiber-test/pkg/model"
 	"micro-fiber-test/pkg/repository/api"
 	svcApi "micro-fiber-test/pkg/service/api"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type UserService struct {
@@ -20,7 +22,7 @@ func (u UserService) Create(defautTenantId int64, user model.User) (int64, error
 	user.TenantId = defautTenantId
 
 	// Login is unique
-	idUsr, _, errLogin := u.dao.IsLoginInUse(user.Login)
+	idUsr, _, errLogin := u.dao.IsLoginInUse(user.TenantId, user.Login)
 	if errLogin != nil {
 		return 0, errLogin
 	}
@@ -29,7 +31,7 @@ func (u UserService) Create(defautTenantId int64, user model.User) (int64, error
 	}
 
 	// Email is unique
-	idUsr, _, errEmail := u.dao.IsEmailInUse(user.Email)
+	idUsr, _, errEmail := u.dao.IsEmailInUse(user.TenantId, user.Email)
 	if errEmail != nil {
 		return 0, errEmail
 	}
@@ -48,7 +50,7 @@ func (u UserService) Create(defautTenantId int64, user model.User) (int64, error
 func (u UserService) Update(user model.User) error {
 
 	// Login is unique
-	_, extId, errLogin := u.dao.IsLoginInUse(user.Login)
+	_, extId, errLogin := u.dao.IsLoginInUse(user.TenantId, user.Login)
 	if errLogin != nil {
 		return errLogin
 	}
@@ -57,7 +59,7 @@ func (u UserService) Update(user model.User) error {
 	}
 
 	// Email is unique
-	_, extId, errEmail := u.dao.IsEmailInUse(user.Email)
+	_, extId, errEmail := u.dao.IsEmailInUse(user.TenantId, user.Email)
 	if errEmail != nil {
 		return errEmail
 	}
@@ -65,7 +67,7 @@ func (u UserService) Update(user model.User) error {
 		return errors.New(commons.UserEmailAlreadyInUse)
 	}
 
-	return u.dao.Update(user)
+	return checkUpdated(u.dao.Update(user))
 }
 
 func (u UserService) FindByCriteria(criteria model.UserFilterCriteria) (model.UserSearchResult, error) {
@@ -85,6 +87,17 @@ func (u UserService) FindByCode(tenantId int64, orgId int64, externalId string)
 	return u.dao.FindByExternalId(tenantId, orgId, externalId)
 }
 
-func (u UserService) Delete(externalId string) error {
-	return u.dao.Delete(externalId)
+func (u UserService) Delete(tenantId int64, externalId string, version int64) error {
+	return checkUpdated(u.dao.Delete(tenantId, externalId, version))
+}
+
+func (u UserService) Restore(tenantId int64, orgId int64, externalId string) error {
+	_, errFind := u.dao.FindDeletedByExternalId(tenantId, orgId, externalId)
+	if errors.Is(errFind, pgx.ErrNoRows) {
+		return errors.New(commons.UserNotFound)
+	}
+	if errFind != nil {
+		return errFind
+	}
+	return u.dao.Restore(tenantId, externalId)
 }
@@ -0,0 +1,25 @@
+package impl
+
+import (
+	"errors"
+	"micro-fiber-test/pkg/dto/commons"
+)
+
+// checkVersion compares the version expected by the client with the current one, 0 means no expectation
+func checkVersion(expected int64, current int64) error {
+	if expected != 0 && expected != current {
+		return errors.New(commons.VersionMismatch)
+	}
+	return nil
+}
+
+// checkUpdated turns the result of a versioned update into an error, no updated row means the resource changed in between
+func checkUpdated(rows int64, errUpdate error) error {
+	if errUpdate != nil {
+		return errUpdate
+	}
+	if rows == 0 {
+		return errors.New(commons.VersionMismatch)
+	}
+	return nil
+}
@@ -11,6 +11,10 @@ const (
 	FieldRequired          = "Field %s is required"
 	FieldMinLength         = "Field %s min length [%s] validation failed"
 	FieldMaxLength         = "Field %s max length [%s] validation failed"
+	FieldInvalidValue      = "Field %s value [%s] is not allowed"
+	FieldNotRemovable      = "Field %s cannot be removed"
+	FieldUnknown           = "Field %s is unknown"
+	FieldInvalidFormat     = "Field %s has an invalid format"
 	GlobalValidationFailed = "validation_failed"
 )
 